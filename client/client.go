@@ -0,0 +1,283 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/gorush"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is gorush api client.
+type Client struct {
+	Server     string
+	API        config.SectionAPI
+	HTTPClient *http.Client
+	// APIKey is bearer token sent in Authorization header when auth engine is not none.
+	APIKey string
+	// Retry is max retry count when server is unreachable, or response 5xx to GET request.
+	// POST request is retried only if connection failed, so notifications are not sent twice.
+	Retry     int
+	RetryWait time.Duration
+}
+
+// ErrorResponse is gorush api error message.
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// ErrorCode and Field are set for validation error of notification.
+	ErrorCode string `json:"error_code,omitempty"`
+	Field     string `json:"field,omitempty"`
+}
+
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("gorush: %d %s", e.Code, e.Message)
+}
+
+// New create gorush api client with default api path.
+func New(server string) *Client {
+	return &Client{
+		Server:     strings.TrimRight(server, "/"),
+		API:        config.BuildDefaultPushConf().API,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Retry:      3,
+		RetryWait:  500 * time.Millisecond,
+	}
+}
+
+// Send push single notification.
+func (c *Client) Send(notification gorush.PushNotification) error {
+	return c.SendBatch([]gorush.PushNotification{notification})
+}
+
+// SendBatch push multiple notifications in one request.
+func (c *Client) SendBatch(notifications []gorush.PushNotification) error {
+	if len(notifications) == 0 {
+		return errors.New("notifications must not be empty")
+	}
+
+	body, err := json.Marshal(gorush.RequestPush{
+		Notifications: notifications,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return c.do("POST", c.API.PushURI, body, nil)
+}
+
+// SendSingle push notification of one token immediately and return provider response.
+func (c *Client) SendSingle(notification gorush.PushNotification) (*gorush.SingleResponse, error) {
+	body, err := json.Marshal(notification)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gorush.SingleResponse{}
+
+	if err := c.do("POST", c.API.SinglePushURI, body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Maintenance stop or resume dequeuing notifications of server,
+// undelivered notifications are exported to file of server if export is true.
+func (c *Client) Maintenance(enabled, export bool) (*gorush.MaintenanceStatus, error) {
+	body, err := json.Marshal(gorush.RequestMaintenance{
+		Enabled: enabled,
+		Export:  export,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gorush.MaintenanceStatus{}
+
+	if err := c.do("POST", c.API.MaintenanceURI, body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ExportQueue dequeue pending notifications of server in maintenance mode.
+func (c *Client) ExportQueue() ([]gorush.PushNotification, error) {
+	result := &gorush.QueueSnapshot{}
+
+	if err := c.do("POST", c.API.QueueExportURI, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result.Notifications, nil
+}
+
+// ImportQueue enqueue exported notifications into queue of server.
+func (c *Client) ImportQueue(notifications []gorush.PushNotification) (*gorush.ImportResult, error) {
+	body, err := json.Marshal(gorush.QueueSnapshot{
+		Notifications: notifications,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gorush.ImportResult{}
+
+	if err := c.do("POST", c.API.QueueImportURI, body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetApp enable or disable app (iOS topic or Android package name) of server,
+// queued notifications of disabled app are parked until it is enabled if park is true.
+func (c *Client) SetApp(app string, enabled, park bool) (*gorush.AppStatus, error) {
+	body, err := json.Marshal(gorush.RequestApp{
+		App:     app,
+		Enabled: enabled,
+		Park:    park,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gorush.AppStatus{}
+
+	if err := c.do("POST", c.API.AppURI, body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Status get notification success and failure counts.
+func (c *Client) Status() (*gorush.StatusApp, error) {
+	result := &gorush.StatusApp{}
+
+	if err := c.do("GET", c.API.StatAppURI, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Summary get aggregate push result of request, request ID is returned by Send or
+// set in X-Request-ID header.
+func (c *Client) Summary(requestID string) (*gorush.PushSummary, error) {
+	result := &gorush.PushSummary{}
+
+	if err := c.do("GET", c.API.SummaryURI+"?request_id="+url.QueryEscape(requestID), nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Stats get response time, status code count, etc.
+func (c *Client) Stats() (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	if err := c.do("GET", c.API.SysStatURI, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) do(method, uri string, body []byte, result interface{}) error {
+	var err error
+
+	for i := 0; i <= c.Retry; i++ {
+		if i > 0 {
+			time.Sleep(c.RetryWait * time.Duration(i))
+		}
+
+		var retry bool
+		retry, err = c.request(method, uri, body, result)
+
+		if err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) request(method, uri string, body []byte, result interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.Server+uri, reader)
+
+	if err != nil {
+		return false, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+
+	if err != nil {
+		// request is not written if server is unreachable.
+		return method == "GET" || isDialError(err), err
+	}
+
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		// request is already handled by server, only GET is safe to retry.
+		return method == "GET", err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		errRes := &ErrorResponse{}
+		if json.Unmarshal(data, errRes) != nil || errRes.Code == 0 {
+			errRes.Code = res.StatusCode
+			errRes.Message = http.StatusText(res.StatusCode)
+		}
+
+		return method == "GET" && res.StatusCode >= http.StatusInternalServerError, errRes
+	}
+
+	if result == nil {
+		return false, nil
+	}
+
+	return false, json.Unmarshal(data, result)
+}
+
+// isDialError return true if connection to server failed before request is written.
+func isDialError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	opErr, ok := err.(*net.OpError)
+
+	return ok && opErr.Op == "dial"
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/gorush"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendNotification(t *testing.T) {
+	var form gorush.RequestPush
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/push", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&form)
+		w.Write([]byte(`{"success":"ok"}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	err := c.Send(gorush.PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: gorush.PlatFormAndroid,
+		Message:  "Welcome",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(form.Notifications))
+	assert.Equal(t, "Welcome", form.Notifications[0].Message)
+}
+
+func TestSendSingleNotification(t *testing.T) {
+	var form gorush.PushNotification
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/push/single", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&form)
+		w.Write([]byte(`{"platform":"ios","success":false,"status_code":400,"reason":"BadDeviceToken","latency":120}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	result, err := c.SendSingle(gorush.PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: gorush.PlatFormIos,
+		Message:  "Welcome",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome", form.Message)
+	assert.False(t, result.Success)
+	assert.Equal(t, 400, result.StatusCode)
+	assert.Equal(t, "BadDeviceToken", result.Reason)
+	assert.Equal(t, int64(120), result.Latency)
+}
+
+func TestMaintenance(t *testing.T) {
+	var form gorush.RequestMaintenance
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/maintenance", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&form)
+		w.Write([]byte(`{"maintenance":true,"queue_usage":0,"exported":10}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	result, err := c.Maintenance(true, true)
+
+	assert.NoError(t, err)
+	assert.True(t, form.Enabled)
+	assert.True(t, form.Export)
+	assert.True(t, result.Maintenance)
+	assert.Equal(t, 10, result.Exported)
+}
+
+func TestAPIKey(t *testing.T) {
+	var headers []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"success":"ok"}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	assert.NoError(t, c.Send(gorush.PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"}))
+
+	c.APIKey = "secret"
+	assert.NoError(t, c.Send(gorush.PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"}))
+	c.Status()
+
+	assert.Equal(t, []string{"", "Bearer secret", "Bearer secret"}, headers)
+}
+
+func TestSendEmptyNotifications(t *testing.T) {
+	c := New("http://localhost:8088")
+
+	assert.Error(t, c.SendBatch(nil))
+}
+
+func TestBadRequestWithoutRetry(t *testing.T) {
+	count := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"message":"Notifications field is empty."}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	err := c.Send(gorush.PushNotification{})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "Notifications field is empty.", err.(*ErrorResponse).Message)
+}
+
+func TestRetryServerError(t *testing.T) {
+	count := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"version":"v1.0.0","total_count":10}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	c.RetryWait = time.Millisecond
+	status, err := c.Status()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "v1.0.0", status.Version)
+	assert.Equal(t, int64(10), status.TotalCount)
+}
+
+func TestSendWithoutRetry(t *testing.T) {
+	count := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	c := New(ts.URL)
+	c.RetryWait = time.Millisecond
+
+	// server may have queued notifications before 5xx.
+	assert.Error(t, c.Send(gorush.PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"}))
+	assert.Equal(t, 1, count)
+
+	ts.Close()
+
+	// response body is broken after server accepted the request.
+	count = 0
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"success":"ok"`))
+	}))
+
+	c = New(ts.URL)
+	c.RetryWait = time.Millisecond
+
+	assert.Error(t, c.Send(gorush.PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"}))
+	assert.Equal(t, 1, count)
+
+	// connection is refused before request is written.
+	ts.Close()
+	err := c.Send(gorush.PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"})
+	assert.Error(t, err)
+	assert.True(t, isDialError(err))
+}
+
+func TestSetApp(t *testing.T) {
+	var form gorush.RequestApp
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/app", r.URL.Path)
+		json.NewDecoder(r.Body).Decode(&form)
+		w.Write([]byte(`{"app":"com.example.app","enabled":false,"park":true,"parked":0}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	result, err := c.SetApp("com.example.app", false, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "com.example.app", form.App)
+	assert.False(t, form.Enabled)
+	assert.True(t, form.Park)
+	assert.True(t, result.Park)
+}
+
+func TestSummary(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stat/summary", r.URL.Path)
+		assert.Equal(t, "request-1", r.URL.Query().Get("request_id"))
+		w.Write([]byte(`{"request_id":"request-1","push_success":8,"push_error":2,"errors":{"BadDeviceToken":2}}`))
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	result, err := c.Summary("request-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), result.PushSuccess)
+	assert.Equal(t, int64(2), result.Errors["BadDeviceToken"])
+}
+
+func TestExportAndImportQueue(t *testing.T) {
+	var form gorush.QueueSnapshot
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/queue/export":
+			w.Write([]byte(`{"notifications":[{"tokens":["aaaaa"],"platform":1,"message":"Hello"}]}`))
+		case "/api/queue/import":
+			json.NewDecoder(r.Body).Decode(&form)
+			w.Write([]byte(`{"imported":1,"queue_usage":1}`))
+		default:
+			t.Errorf("wrong path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	notifications, err := c.ExportQueue()
+
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, "Hello", notifications[0].Message)
+
+	result, err := c.ImportQueue(notifications)
+
+	assert.NoError(t, err)
+	assert.Len(t, form.Notifications, 1)
+	assert.Equal(t, 1, result.Imported)
+}
@@ -6,61 +6,234 @@ import (
 	"runtime"
 )
 
+// Providers is name of webhook providers in stats.
+var Providers = []string{"ntfy", "gotify", "telegram", "slack", "teams"}
+
 // ConfYaml is config structure.
 type ConfYaml struct {
-	Core    SectionCore    `yaml:"core"`
-	API     SectionAPI     `yaml:"api"`
-	Android SectionAndroid `yaml:"android"`
-	Ios     SectionIos     `yaml:"ios"`
-	Log     SectionLog     `yaml:"log"`
-	Stat    SectionStat    `yaml:"stat"`
+	Core     SectionCore     `yaml:"core"`
+	API      SectionAPI      `yaml:"api"`
+	Android  SectionAndroid  `yaml:"android"`
+	Ios      SectionIos      `yaml:"ios"`
+	Ntfy     SectionNtfy     `yaml:"ntfy"`
+	Gotify   SectionGotify   `yaml:"gotify"`
+	Telegram SectionTelegram `yaml:"telegram"`
+	Slack    SectionWebhook  `yaml:"slack"`
+	Teams    SectionWebhook  `yaml:"teams"`
+	Log      SectionLog      `yaml:"log"`
+	Stat     SectionStat     `yaml:"stat"`
+	Callback SectionCallback `yaml:"callback"`
+	Inbound  SectionInbound  `yaml:"inbound"`
 }
 
 // SectionCore is sub seciont of config.
 type SectionCore struct {
-	Port            string     `yaml:"port"`
-	MaxNotification int64      `yaml:"max_notification"`
-	WorkerNum       int64      `yaml:"worker_num"`
-	QueueNum        int64      `yaml:"queue_num"`
-	Mode            string     `yaml:"mode"`
-	SSL             bool       `yaml:"ssl"`
-	CertPath        string     `yaml:"cert_path"`
-	KeyPath         string     `yaml:"key_path"`
-	HTTPProxy       string     `yaml:"http_proxy"`
-	PID             SectionPID `yaml:"pid"`
+	Port            string           `yaml:"port"`
+	MaxNotification int64            `yaml:"max_notification"`
+	MaxBodySize     int64            `yaml:"max_body_size"`
+	MaxTokens       int              `yaml:"max_tokens"`
+	MaxDataSize     int              `yaml:"max_data_size"`
+	WorkerNum       int64            `yaml:"worker_num"`
+	QueueNum        int64            `yaml:"queue_num"`
+	QueueEngine     string           `yaml:"queue_engine"`
+	FastLaneTokens  int              `yaml:"fast_lane_tokens"`
+	StreamPush      bool             `yaml:"stream_push"`
+	QueueExportPath string           `yaml:"queue_export_path"`
+	QueueAgeAlert   int              `yaml:"queue_age_alert"`
+	DrainTimeout    int              `yaml:"drain_timeout"`
+	ValidateToken   bool             `yaml:"validate_token"`
+	Mode            string           `yaml:"mode"`
+	SSL             bool             `yaml:"ssl"`
+	CertPath        string           `yaml:"cert_path"`
+	KeyPath         string           `yaml:"key_path"`
+	HTTPProxy       string           `yaml:"http_proxy"`
+	PID             SectionPID       `yaml:"pid"`
+	Outage          SectionOutage    `yaml:"outage"`
+	Throttle        SectionThrottle  `yaml:"throttle"`
+	Chaos           SectionChaos     `yaml:"chaos"`
+	Preflight       SectionPreflight `yaml:"preflight"`
+	Access          SectionAccess    `yaml:"access"`
+	Auth            SectionAuth      `yaml:"auth"`
+	Rejected        SectionRejected  `yaml:"rejected"`
 }
 
 // SectionAPI is sub seciont of config.
 type SectionAPI struct {
-	PushURI    string `yaml:"push_uri"`
-	StatGoURI  string `yaml:"stat_go_uri"`
-	StatAppURI string `yaml:"stat_app_uri"`
-	ConfigURI  string `yaml:"config_uri"`
-	SysStatURI string `yaml:"sys_stat_uri"`
+	PushURI        string `yaml:"push_uri"`
+	SinglePushURI  string `yaml:"single_push_uri"`
+	PreviewURI     string `yaml:"preview_uri"`
+	MaintenanceURI string `yaml:"maintenance_uri"`
+	AppURI         string `yaml:"app_uri"`
+	QueueExportURI string `yaml:"queue_export_uri"`
+	QueueImportURI string `yaml:"queue_import_uri"`
+	InboundURI     string `yaml:"inbound_uri"`
+	StatGoURI      string `yaml:"stat_go_uri"`
+	StatAppURI     string `yaml:"stat_app_uri"`
+	SummaryURI     string `yaml:"summary_uri"`
+	EventsURI      string `yaml:"events_uri"`
+	// EventsOrigins is allowed Origin of events WebSocket besides host of request.
+	EventsOrigins []string `yaml:"events_origins"`
+	RejectedURI   string   `yaml:"rejected_uri"`
+	ConfigURI     string   `yaml:"config_uri"`
+	SysStatURI    string   `yaml:"sys_stat_uri"`
 }
 
 // SectionAndroid is sub seciont of config.
 type SectionAndroid struct {
-	Enabled bool   `yaml:"enabled"`
-	APIKey  string `yaml:"apikey"`
+	Enabled        bool             `yaml:"enabled"`
+	APIKey         string           `yaml:"apikey"`
+	PackageName    string           `yaml:"package_name"`
+	EnforcePackage bool             `yaml:"enforce_package"`
+	ProxyCert      string           `yaml:"proxy_cert"`
+	ProxyKey       string           `yaml:"proxy_key"`
+	FallbackProxy  string           `yaml:"fallback_proxy"`
+	MaxTokens      int              `yaml:"max_tokens"`
+	MaxDataSize    int              `yaml:"max_data_size"`
+	Transform      SectionTransform `yaml:"transform"`
+	Log            SectionPushLog   `yaml:"log"`
+	Encrypt        SectionEncrypt   `yaml:"encrypt"`
+	Batch          SectionBatch     `yaml:"batch"`
+	Quiet          SectionQuiet     `yaml:"quiet_hours"`
+	HTTP           SectionHTTP      `yaml:"http"`
+	TestTokens     []string         `yaml:"test_tokens"`
+	TrimPayload    bool             `yaml:"trim_payload"`
+	DataSchema     string           `yaml:"data_schema"`
+	HashSalt       string           `yaml:"hash_salt"`
 }
 
 // SectionIos is sub seciont of config.
 type SectionIos struct {
-	Enabled    bool   `yaml:"enabled"`
-	KeyPath    string `yaml:"key_path"`
-	Password   string `yaml:"password"`
-	Production bool   `yaml:"production"`
+	Enabled       bool             `yaml:"enabled"`
+	KeyPath       string           `yaml:"key_path"`
+	Password      string           `yaml:"password"`
+	Production    bool             `yaml:"production"`
+	Proxy         string           `yaml:"proxy"`
+	FallbackProxy string           `yaml:"fallback_proxy"`
+	LazyInit      bool             `yaml:"lazy_init"`
+	MaxTokens     int              `yaml:"max_tokens"`
+	MaxDataSize   int              `yaml:"max_data_size"`
+	Certs         []SectionIosCert `yaml:"certs"`
+	Transform     SectionTransform `yaml:"transform"`
+	Log           SectionPushLog   `yaml:"log"`
+	Encrypt       SectionEncrypt   `yaml:"encrypt"`
+	Quiet         SectionQuiet     `yaml:"quiet_hours"`
+	HTTP          SectionHTTP      `yaml:"http"`
+	TestTokens    []string         `yaml:"test_tokens"`
+	TrimPayload   bool             `yaml:"trim_payload"`
+	DataSchema    string           `yaml:"data_schema"`
+	HashSalt      string           `yaml:"hash_salt"`
+}
+
+// SectionNtfy is sub seciont of config.
+// Token is access token of ntfy server, empty for public topics.
+type SectionNtfy struct {
+	Enabled   bool   `yaml:"enabled"`
+	ServerURL string `yaml:"server_url"`
+	Token     string `yaml:"token"`
+}
+
+// SectionGotify is sub seciont of config.
+type SectionGotify struct {
+	Enabled   bool   `yaml:"enabled"`
+	ServerURL string `yaml:"server_url"`
+}
+
+// SectionTelegram is sub seciont of config.
+type SectionTelegram struct {
+	Enabled  bool   `yaml:"enabled"`
+	BotToken string `yaml:"bot_token"`
+	APIURL   string `yaml:"api_url"`
+}
+
+// SectionWebhook is sub seciont of config.
+// Webhooks map ID to webhook URL, so token of notification can be ID instead of URL.
+type SectionWebhook struct {
+	Enabled  bool              `yaml:"enabled"`
+	Webhooks map[string]string `yaml:"webhooks"`
+}
+
+// SectionIosCert is sub seciont of config.
+// Certificate is selected by topic (bundle ID) of notification.
+type SectionIosCert struct {
+	Topic    string `yaml:"topic"`
+	KeyPath  string `yaml:"key_path"`
+	Password string `yaml:"password"`
+	// Disabled app rejects new pushes, it can be enabled at runtime by app api.
+	Disabled bool `yaml:"disabled"`
+	// TestTokens overwrite test_tokens of ios section for topic.
+	TestTokens []string `yaml:"test_tokens"`
+	// TrimPayload enable trim_payload for topic.
+	TrimPayload bool `yaml:"trim_payload"`
+	// DataSchema overwrite data_schema of ios section for topic.
+	DataSchema string `yaml:"data_schema"`
+	// HashSalt overwrite hash_salt of ios section for topic.
+	HashSalt string `yaml:"hash_salt"`
+}
+
+// SectionTransform is sub seciont of config.
+// Rules are applied to the data field in order: strip, rename and inject.
+type SectionTransform struct {
+	Strip  []string          `yaml:"strip"`
+	Rename map[string]string `yaml:"rename"`
+	Inject map[string]string `yaml:"inject"`
+}
+
+// SectionEncrypt is sub seciont of config.
+// Key is base64 encoded AES key (16, 24 or 32 bytes).
+type SectionEncrypt struct {
+	Enabled bool   `yaml:"enabled"`
+	KeyID   string `yaml:"key_id"`
+	Key     string `yaml:"key"`
+}
+
+// SectionBatch is sub seciont of config.
+// Window is milliseconds to merge notifications with identical payload.
+type SectionBatch struct {
+	Enabled bool `yaml:"enabled"`
+	Window  int  `yaml:"window"`
+}
+
+// SectionQuiet is sub seciont of config.
+// Start and End are local time of timezone in 15:04 format.
+// Action is hold (send after quiet hours) or drop.
+type SectionQuiet struct {
+	Enabled  bool   `yaml:"enabled"`
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	Timezone string `yaml:"timezone"`
+	Action   string `yaml:"action"`
+}
+
+// SectionHTTP is sub seciont of config.
+// Tuning of provider HTTP transport, 0 is using Go default.
+// IdleConnTimeout is seconds to keep idle connection.
+// RootCA is extra root CA PEM file or directory.
+type SectionHTTP struct {
+	MaxIdleConns    int    `yaml:"max_idle_conns"`
+	IdleConnTimeout int    `yaml:"idle_conn_timeout"`
+	TLSSessionCache int    `yaml:"tls_session_cache"`
+	RootCA          string `yaml:"root_ca"`
+}
+
+// SectionPushLog is sub seciont of config.
+// Push log of platform is written to default access and error log if path is empty.
+type SectionPushLog struct {
+	Path   string `yaml:"path"`
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
 }
 
 // SectionLog is sub seciont of config.
 type SectionLog struct {
+	Engine      string `yaml:"engine"`
 	Format      string `yaml:"format"`
 	AccessLog   string `yaml:"access_log"`
 	AccessLevel string `yaml:"access_level"`
 	ErrorLog    string `yaml:"error_log"`
 	ErrorLevel  string `yaml:"error_level"`
 	HideToken   bool   `yaml:"hide_token"`
+	HashToken   bool   `yaml:"hash_token"`
+	HashSalt    string `yaml:"hash_salt"`
 }
 
 // SectionStat is sub seciont of config.
@@ -70,6 +243,7 @@ type SectionStat struct {
 	BoltDB  SectionBoltDB  `yaml:"boltdb"`
 	BuntDB  SectionBuntDB  `yaml:"buntdb"`
 	LevelDB SectionLevelDB `yaml:"leveldb"`
+	Statsd  SectionStatsd  `yaml:"statsd"`
 }
 
 // SectionRedis is sub seciont of config.
@@ -95,6 +269,33 @@ type SectionLevelDB struct {
 	Path string `yaml:"path"`
 }
 
+// SectionStatsd is sub seciont of config.
+type SectionStatsd struct {
+	Enabled bool     `yaml:"enabled"`
+	Addr    string   `yaml:"addr"`
+	Prefix  string   `yaml:"prefix"`
+	Tags    []string `yaml:"tags"`
+}
+
+// SectionCallback is sub seciont of config.
+type SectionCallback struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	// SampleRate is ratio of succeeded results to post, failed results are always posted.
+	SampleRate    float64 `yaml:"sample_rate"`
+	BatchSize     int     `yaml:"batch_size"`
+	FlushInterval int     `yaml:"flush_interval"`
+}
+
+// SectionInbound is sub seciont of config.
+// Mapping is field of notification to dot path of webhook JSON body, e.g. {"tokens": "user.push_tokens"}.
+type SectionInbound struct {
+	Enabled  bool              `yaml:"enabled"`
+	Secret   string            `yaml:"secret"`
+	Platform int               `yaml:"platform"`
+	Mapping  map[string]string `yaml:"mapping"`
+}
+
 // SectionPID is sub seciont of config.
 type SectionPID struct {
 	Enabled  bool   `yaml:"enabled"`
@@ -102,6 +303,72 @@ type SectionPID struct {
 	Override bool   `yaml:"override"`
 }
 
+// SectionOutage is sub seciont of config.
+// Push of platform is paused after threshold consecutive provider failures
+// and resumed once a probe push succeeds.
+type SectionOutage struct {
+	Enabled       bool `yaml:"enabled"`
+	Threshold     int  `yaml:"threshold"`
+	ProbeInterval int  `yaml:"probe_interval"`
+}
+
+// SectionThrottle is sub seciont of config.
+// Send concurrency of app is halved and backed off when provider
+// throttles push, then ramped back up to worker number.
+type SectionThrottle struct {
+	Enabled        bool `yaml:"enabled"`
+	MinConcurrency int  `yaml:"min_concurrency"`
+	Backoff        int  `yaml:"backoff"`
+	MaxBackoff     int  `yaml:"max_backoff"`
+}
+
+// SectionChaos is sub seciont of config.
+// Failure injection for testing, not working in release mode.
+type SectionChaos struct {
+	Enabled    bool    `yaml:"enabled"`
+	DropRate   float64 `yaml:"drop_rate"`
+	Delay      int     `yaml:"delay"`
+	ApnsReason string  `yaml:"apns_reason"`
+}
+
+// SectionAccess is sub seciont of config.
+// Client IP in deny list or not in allow list is rejected, empty allow list allows all IPs.
+type SectionAccess struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// SectionAuth is sub seciont of config.
+// Engine is none, static, jwt or custom engine registered by gorush.RegisterAuth.
+type SectionAuth struct {
+	Engine    string           `yaml:"engine"`
+	Keys      []SectionAuthKey `yaml:"keys"`
+	JWTSecret string           `yaml:"jwt_secret"`
+	JWTIssuer string           `yaml:"jwt_issuer"`
+}
+
+// SectionAuthKey is sub seciont of config.
+type SectionAuthKey struct {
+	Name   string   `yaml:"name"`
+	Key    string   `yaml:"key"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// SectionPreflight is sub seciont of config.
+// Verify provider credentials on startup, action is warn or fail.
+type SectionPreflight struct {
+	Enabled bool   `yaml:"enabled"`
+	Action  string `yaml:"action"`
+}
+
+// SectionRejected is sub seciont of config.
+// Keep the last size rejected push requests with redacted body up to max_body bytes.
+type SectionRejected struct {
+	Enabled bool `yaml:"enabled"`
+	Size    int  `yaml:"size"`
+	MaxBody int  `yaml:"max_body"`
+}
+
 // BuildDefaultPushConf is default config setting.
 func BuildDefaultPushConf() ConfYaml {
 	var conf ConfYaml
@@ -110,40 +377,161 @@ func BuildDefaultPushConf() ConfYaml {
 	conf.Core.Port = "8088"
 	conf.Core.WorkerNum = int64(runtime.NumCPU())
 	conf.Core.QueueNum = int64(8192)
+	conf.Core.QueueEngine = "channel"
+	conf.Core.FastLaneTokens = 0
+	conf.Core.StreamPush = false
+	conf.Core.QueueExportPath = "gorush-queue.json"
+	conf.Core.QueueAgeAlert = 0
+	conf.Core.DrainTimeout = 30
+	conf.Core.ValidateToken = false
 	conf.Core.Mode = "release"
 	conf.Core.SSL = false
 	conf.Core.CertPath = "cert.pem"
 	conf.Core.KeyPath = "key.pem"
 	conf.Core.MaxNotification = int64(100)
+	conf.Core.MaxBodySize = int64(0)
+	conf.Core.MaxTokens = 0
+	conf.Core.MaxDataSize = 0
 	conf.Core.HTTPProxy = ""
 	conf.Core.PID.Enabled = false
 	conf.Core.PID.Path = "gorush.pid"
 	conf.Core.PID.Override = false
+	conf.Core.Outage.Enabled = false
+	conf.Core.Outage.Threshold = 50
+	conf.Core.Outage.ProbeInterval = 30
+	conf.Core.Throttle.Enabled = false
+	conf.Core.Throttle.MinConcurrency = 1
+	conf.Core.Throttle.Backoff = 1
+	conf.Core.Throttle.MaxBackoff = 60
+	conf.Core.Chaos.Enabled = false
+	conf.Core.Chaos.DropRate = 0
+	conf.Core.Chaos.Delay = 0
+	conf.Core.Chaos.ApnsReason = ""
+	conf.Core.Preflight.Enabled = false
+	conf.Core.Preflight.Action = "warn"
+	conf.Core.Access.Allow = []string{}
+	conf.Core.Access.Deny = []string{}
+	conf.Core.Auth.Engine = "none"
+	conf.Core.Auth.Keys = []SectionAuthKey{}
+	conf.Core.Auth.JWTSecret = ""
+	conf.Core.Auth.JWTIssuer = ""
+	conf.Core.Rejected.Enabled = false
+	conf.Core.Rejected.Size = 20
+	conf.Core.Rejected.MaxBody = 4096
 
 	// Api
 	conf.API.PushURI = "/api/push"
+	conf.API.SinglePushURI = "/api/push/single"
+	conf.API.PreviewURI = "/api/preview"
+	conf.API.MaintenanceURI = "/api/maintenance"
+	conf.API.AppURI = "/api/app"
+	conf.API.QueueExportURI = "/api/queue/export"
+	conf.API.QueueImportURI = "/api/queue/import"
+	conf.API.InboundURI = "/api/inbound"
 	conf.API.StatGoURI = "/api/stat/go"
 	conf.API.StatAppURI = "/api/stat/app"
+	conf.API.SummaryURI = "/api/stat/summary"
+	conf.API.EventsURI = "/api/events"
+	conf.API.EventsOrigins = []string{}
+	conf.API.RejectedURI = "/api/push/rejected"
 	conf.API.ConfigURI = "/api/config"
 	conf.API.SysStatURI = "/sys/stats"
 
 	// Android
 	conf.Android.Enabled = false
 	conf.Android.APIKey = ""
+	conf.Android.PackageName = ""
+	conf.Android.EnforcePackage = false
+	conf.Android.ProxyCert = ""
+	conf.Android.ProxyKey = ""
+	conf.Android.FallbackProxy = ""
+	conf.Android.MaxTokens = 0
+	conf.Android.MaxDataSize = 0
+	conf.Android.Log.Path = ""
+	conf.Android.Log.Level = "info"
+	conf.Android.Log.Format = "string"
+	conf.Android.Encrypt.Enabled = false
+	conf.Android.Encrypt.KeyID = ""
+	conf.Android.Encrypt.Key = ""
+	conf.Android.Batch.Enabled = false
+	conf.Android.Batch.Window = 100
+	conf.Android.Quiet.Enabled = false
+	conf.Android.Quiet.Start = "22:00"
+	conf.Android.Quiet.End = "07:00"
+	conf.Android.Quiet.Timezone = "UTC"
+	conf.Android.Quiet.Action = "hold"
+	conf.Android.HTTP.MaxIdleConns = 0
+	conf.Android.HTTP.IdleConnTimeout = 0
+	conf.Android.HTTP.TLSSessionCache = 0
+	conf.Android.HTTP.RootCA = ""
+	conf.Android.TestTokens = []string{}
+	conf.Android.TrimPayload = false
+	conf.Android.DataSchema = ""
+	conf.Android.HashSalt = ""
 
 	// iOS
 	conf.Ios.Enabled = false
 	conf.Ios.KeyPath = "key.pem"
 	conf.Ios.Password = ""
 	conf.Ios.Production = false
+	conf.Ios.Proxy = ""
+	conf.Ios.FallbackProxy = ""
+	conf.Ios.LazyInit = false
+	conf.Ios.MaxTokens = 0
+	conf.Ios.MaxDataSize = 0
+	conf.Ios.Certs = []SectionIosCert{}
+	conf.Ios.Log.Path = ""
+	conf.Ios.Log.Level = "info"
+	conf.Ios.Log.Format = "string"
+	conf.Ios.Encrypt.Enabled = false
+	conf.Ios.Encrypt.KeyID = ""
+	conf.Ios.Encrypt.Key = ""
+	conf.Ios.Quiet.Enabled = false
+	conf.Ios.Quiet.Start = "22:00"
+	conf.Ios.Quiet.End = "07:00"
+	conf.Ios.Quiet.Timezone = "UTC"
+	conf.Ios.Quiet.Action = "hold"
+	conf.Ios.HTTP.MaxIdleConns = 0
+	conf.Ios.HTTP.IdleConnTimeout = 0
+	conf.Ios.HTTP.TLSSessionCache = 0
+	conf.Ios.HTTP.RootCA = ""
+	conf.Ios.TestTokens = []string{}
+	conf.Ios.TrimPayload = false
+	conf.Ios.DataSchema = ""
+	conf.Ios.HashSalt = ""
+
+	// ntfy
+	conf.Ntfy.Enabled = false
+	conf.Ntfy.ServerURL = "https://ntfy.sh"
+	conf.Ntfy.Token = ""
+
+	// Gotify
+	conf.Gotify.Enabled = false
+	conf.Gotify.ServerURL = ""
+
+	// Telegram
+	conf.Telegram.Enabled = false
+	conf.Telegram.BotToken = ""
+	conf.Telegram.APIURL = "https://api.telegram.org"
+
+	// Slack
+	conf.Slack.Enabled = false
+	conf.Slack.Webhooks = map[string]string{}
+
+	// Microsoft Teams
+	conf.Teams.Enabled = false
+	conf.Teams.Webhooks = map[string]string{}
 
 	// log
+	conf.Log.Engine = "logrus"
 	conf.Log.Format = "string"
 	conf.Log.AccessLog = "stdout"
 	conf.Log.AccessLevel = "debug"
 	conf.Log.ErrorLog = "stderr"
 	conf.Log.ErrorLevel = "error"
 	conf.Log.HideToken = true
+	conf.Log.HashToken = false
+	conf.Log.HashSalt = ""
 
 	conf.Stat.Engine = "memory"
 	conf.Stat.Redis.Addr = "localhost:6379"
@@ -156,12 +544,37 @@ func BuildDefaultPushConf() ConfYaml {
 	conf.Stat.BuntDB.Path = "bunt.db"
 	conf.Stat.LevelDB.Path = "level.db"
 
+	conf.Stat.Statsd.Enabled = false
+	conf.Stat.Statsd.Addr = "localhost:8125"
+	conf.Stat.Statsd.Prefix = "gorush"
+	conf.Stat.Statsd.Tags = []string{}
+
+	// Callback
+	conf.Callback.Enabled = false
+	conf.Callback.URL = ""
+	conf.Callback.SampleRate = 1.0
+	conf.Callback.BatchSize = 100
+	conf.Callback.FlushInterval = 5
+
+	// Inbound
+	conf.Inbound.Enabled = false
+	conf.Inbound.Secret = ""
+	conf.Inbound.Platform = 0
+	conf.Inbound.Mapping = map[string]string{
+		"tokens":   "tokens",
+		"platform": "platform",
+		"message":  "message",
+		"title":    "title",
+		"data":     "data",
+	}
+
 	return conf
 }
 
 // LoadConfYaml provide load yml config.
+// Missing keys keep default value, so config of older version still works.
 func LoadConfYaml(confPath string) (ConfYaml, error) {
-	var config ConfYaml
+	config := BuildDefaultPushConf()
 
 	configFile, err := ioutil.ReadFile(confPath)
 
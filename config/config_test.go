@@ -37,6 +37,83 @@ func TestWrongYAMLormat(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+// Test config of older version without keys of new features
+func TestLoadOldConfYaml(t *testing.T) {
+	content := []byte(`core:
+  port: "8088"
+  worker_num: 8
+  queue_num: 8192
+  max_notification: 100
+  mode: "release"
+  ssl: false
+  cert_path: "cert.pem"
+  key_path: "key.pem"
+  http_proxy: ""
+  pid:
+    enabled: false
+    path: "gorush.pid"
+    override: true
+
+api:
+  push_uri: "/api/push"
+  stat_go_uri: "/api/stat/go"
+  stat_app_uri: "/api/stat/app"
+  config_uri: "/api/config"
+  sys_stat_uri: "/sys/stats"
+
+android:
+  enabled: true
+  apikey: "YOUR_API_KEY"
+
+ios:
+  enabled: false
+  key_path: "key.pem"
+  password: ""
+  production: false
+
+log:
+  format: "string" # string or json
+  access_log: "stdout"
+  access_level: "debug"
+  error_log: "stderr"
+  error_level: "error"
+  hide_token: true
+
+stat:
+  engine: "memory"
+  redis:
+    addr: "localhost:6379"
+    password: ""
+    db: 0
+  boltdb:
+    path: "bolt.db"
+    bucket: "gorush"
+  buntdb:
+    path: "bunt.db"
+  leveldb:
+    path: "level.db"
+`)
+
+	filename := "oldconfig.yml"
+
+	if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+		log.Fatalf("WriteFile %s: %v", filename, err)
+	}
+
+	// clean up
+	defer os.Remove(filename)
+
+	conf, err := LoadConfYaml(filename)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "logrus", conf.Log.Engine)
+	assert.Equal(t, "channel", conf.Core.QueueEngine)
+	assert.Equal(t, "none", conf.Core.Auth.Engine)
+	assert.Equal(t, "/api/push/single", conf.API.SinglePushURI)
+	assert.Equal(t, "/api/preview", conf.API.PreviewURI)
+	assert.Equal(t, int64(8192), conf.Core.QueueNum)
+}
+
 type ConfigTestSuite struct {
 	suite.Suite
 	ConfGorushDefault ConfYaml
@@ -57,19 +134,62 @@ func (suite *ConfigTestSuite) TestValidateConfDefault() {
 	assert.Equal(suite.T(), "8088", suite.ConfGorushDefault.Core.Port)
 	assert.Equal(suite.T(), int64(runtime.NumCPU()), suite.ConfGorushDefault.Core.WorkerNum)
 	assert.Equal(suite.T(), int64(8192), suite.ConfGorushDefault.Core.QueueNum)
+	assert.Equal(suite.T(), "channel", suite.ConfGorushDefault.Core.QueueEngine)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Core.FastLaneTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.StreamPush)
+	assert.Equal(suite.T(), "gorush-queue.json", suite.ConfGorushDefault.Core.QueueExportPath)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Core.QueueAgeAlert)
+	assert.Equal(suite.T(), 30, suite.ConfGorushDefault.Core.DrainTimeout)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.ValidateToken)
 	assert.Equal(suite.T(), "release", suite.ConfGorushDefault.Core.Mode)
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.SSL)
 	assert.Equal(suite.T(), "cert.pem", suite.ConfGorushDefault.Core.CertPath)
 	assert.Equal(suite.T(), "key.pem", suite.ConfGorushDefault.Core.KeyPath)
 	assert.Equal(suite.T(), int64(100), suite.ConfGorushDefault.Core.MaxNotification)
+	assert.Equal(suite.T(), int64(0), suite.ConfGorushDefault.Core.MaxBodySize)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Core.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Core.MaxDataSize)
 	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Core.HTTPProxy)
 	// Pid
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.PID.Enabled)
 	assert.Equal(suite.T(), "gorush.pid", suite.ConfGorushDefault.Core.PID.Path)
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.PID.Override)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.Outage.Enabled)
+	assert.Equal(suite.T(), 50, suite.ConfGorushDefault.Core.Outage.Threshold)
+	assert.Equal(suite.T(), 30, suite.ConfGorushDefault.Core.Outage.ProbeInterval)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.Throttle.Enabled)
+	assert.Equal(suite.T(), 1, suite.ConfGorushDefault.Core.Throttle.MinConcurrency)
+	assert.Equal(suite.T(), 1, suite.ConfGorushDefault.Core.Throttle.Backoff)
+	assert.Equal(suite.T(), 60, suite.ConfGorushDefault.Core.Throttle.MaxBackoff)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.Chaos.Enabled)
+	assert.Equal(suite.T(), float64(0), suite.ConfGorushDefault.Core.Chaos.DropRate)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Core.Chaos.Delay)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Core.Chaos.ApnsReason)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.Preflight.Enabled)
+	assert.Equal(suite.T(), "warn", suite.ConfGorushDefault.Core.Preflight.Action)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorushDefault.Core.Access.Allow)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorushDefault.Core.Access.Deny)
+	assert.Equal(suite.T(), "none", suite.ConfGorushDefault.Core.Auth.Engine)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorushDefault.Core.Auth.Keys))
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Core.Auth.JWTSecret)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Core.Auth.JWTIssuer)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Core.Rejected.Enabled)
+	assert.Equal(suite.T(), 20, suite.ConfGorushDefault.Core.Rejected.Size)
+	assert.Equal(suite.T(), 4096, suite.ConfGorushDefault.Core.Rejected.MaxBody)
 
 	// Api
 	assert.Equal(suite.T(), "/api/push", suite.ConfGorushDefault.API.PushURI)
+	assert.Equal(suite.T(), "/api/push/single", suite.ConfGorushDefault.API.SinglePushURI)
+	assert.Equal(suite.T(), "/api/preview", suite.ConfGorushDefault.API.PreviewURI)
+	assert.Equal(suite.T(), "/api/maintenance", suite.ConfGorushDefault.API.MaintenanceURI)
+	assert.Equal(suite.T(), "/api/app", suite.ConfGorushDefault.API.AppURI)
+	assert.Equal(suite.T(), "/api/queue/export", suite.ConfGorushDefault.API.QueueExportURI)
+	assert.Equal(suite.T(), "/api/queue/import", suite.ConfGorushDefault.API.QueueImportURI)
+	assert.Equal(suite.T(), "/api/inbound", suite.ConfGorushDefault.API.InboundURI)
+	assert.Equal(suite.T(), "/api/stat/summary", suite.ConfGorushDefault.API.SummaryURI)
+	assert.Equal(suite.T(), "/api/events", suite.ConfGorushDefault.API.EventsURI)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorushDefault.API.EventsOrigins)
+	assert.Equal(suite.T(), "/api/push/rejected", suite.ConfGorushDefault.API.RejectedURI)
 	assert.Equal(suite.T(), "/api/stat/go", suite.ConfGorushDefault.API.StatGoURI)
 	assert.Equal(suite.T(), "/api/stat/app", suite.ConfGorushDefault.API.StatAppURI)
 	assert.Equal(suite.T(), "/api/config", suite.ConfGorushDefault.API.ConfigURI)
@@ -78,20 +198,98 @@ func (suite *ConfigTestSuite) TestValidateConfDefault() {
 	// Android
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.Enabled)
 	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.APIKey)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.PackageName)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.EnforcePackage)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.ProxyCert)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.ProxyKey)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.FallbackProxy)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Android.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Android.MaxDataSize)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.Log.Path)
+	assert.Equal(suite.T(), "info", suite.ConfGorushDefault.Android.Log.Level)
+	assert.Equal(suite.T(), "string", suite.ConfGorushDefault.Android.Log.Format)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.Encrypt.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.Encrypt.KeyID)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.Encrypt.Key)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.Batch.Enabled)
+	assert.Equal(suite.T(), 100, suite.ConfGorushDefault.Android.Batch.Window)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.Quiet.Enabled)
+	assert.Equal(suite.T(), "22:00", suite.ConfGorushDefault.Android.Quiet.Start)
+	assert.Equal(suite.T(), "07:00", suite.ConfGorushDefault.Android.Quiet.End)
+	assert.Equal(suite.T(), "UTC", suite.ConfGorushDefault.Android.Quiet.Timezone)
+	assert.Equal(suite.T(), "hold", suite.ConfGorushDefault.Android.Quiet.Action)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Android.HTTP.MaxIdleConns)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Android.HTTP.IdleConnTimeout)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Android.HTTP.TLSSessionCache)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.HTTP.RootCA)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorushDefault.Android.TestTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Android.TrimPayload)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.DataSchema)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Android.HashSalt)
 
 	// iOS
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.Enabled)
 	assert.Equal(suite.T(), "key.pem", suite.ConfGorushDefault.Ios.KeyPath)
 	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.Password)
 	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.Production)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.Proxy)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.FallbackProxy)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.LazyInit)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Ios.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Ios.MaxDataSize)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorushDefault.Ios.Certs))
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.Log.Path)
+	assert.Equal(suite.T(), "info", suite.ConfGorushDefault.Ios.Log.Level)
+	assert.Equal(suite.T(), "string", suite.ConfGorushDefault.Ios.Log.Format)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.Encrypt.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.Encrypt.KeyID)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.Encrypt.Key)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.Quiet.Enabled)
+	assert.Equal(suite.T(), "22:00", suite.ConfGorushDefault.Ios.Quiet.Start)
+	assert.Equal(suite.T(), "07:00", suite.ConfGorushDefault.Ios.Quiet.End)
+	assert.Equal(suite.T(), "UTC", suite.ConfGorushDefault.Ios.Quiet.Timezone)
+	assert.Equal(suite.T(), "hold", suite.ConfGorushDefault.Ios.Quiet.Action)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Ios.HTTP.MaxIdleConns)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Ios.HTTP.IdleConnTimeout)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Ios.HTTP.TLSSessionCache)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.HTTP.RootCA)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorushDefault.Ios.TestTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ios.TrimPayload)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.DataSchema)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ios.HashSalt)
+
+	// ntfy
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Ntfy.Enabled)
+	assert.Equal(suite.T(), "https://ntfy.sh", suite.ConfGorushDefault.Ntfy.ServerURL)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Ntfy.Token)
+
+	// Gotify
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Gotify.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Gotify.ServerURL)
+
+	// Telegram
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Telegram.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Telegram.BotToken)
+	assert.Equal(suite.T(), "https://api.telegram.org", suite.ConfGorushDefault.Telegram.APIURL)
+
+	// Slack
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Slack.Enabled)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorushDefault.Slack.Webhooks))
+
+	// Microsoft Teams
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Teams.Enabled)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorushDefault.Teams.Webhooks))
 
 	// log
+	assert.Equal(suite.T(), "logrus", suite.ConfGorushDefault.Log.Engine)
 	assert.Equal(suite.T(), "string", suite.ConfGorushDefault.Log.Format)
 	assert.Equal(suite.T(), "stdout", suite.ConfGorushDefault.Log.AccessLog)
 	assert.Equal(suite.T(), "debug", suite.ConfGorushDefault.Log.AccessLevel)
 	assert.Equal(suite.T(), "stderr", suite.ConfGorushDefault.Log.ErrorLog)
 	assert.Equal(suite.T(), "error", suite.ConfGorushDefault.Log.ErrorLevel)
 	assert.Equal(suite.T(), true, suite.ConfGorushDefault.Log.HideToken)
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Log.HashToken)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Log.HashSalt)
 
 	assert.Equal(suite.T(), "memory", suite.ConfGorushDefault.Stat.Engine)
 	assert.Equal(suite.T(), "localhost:6379", suite.ConfGorushDefault.Stat.Redis.Addr)
@@ -103,6 +301,25 @@ func (suite *ConfigTestSuite) TestValidateConfDefault() {
 
 	assert.Equal(suite.T(), "bunt.db", suite.ConfGorushDefault.Stat.BuntDB.Path)
 	assert.Equal(suite.T(), "level.db", suite.ConfGorushDefault.Stat.LevelDB.Path)
+
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Stat.Statsd.Enabled)
+	assert.Equal(suite.T(), "localhost:8125", suite.ConfGorushDefault.Stat.Statsd.Addr)
+	assert.Equal(suite.T(), "gorush", suite.ConfGorushDefault.Stat.Statsd.Prefix)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorushDefault.Stat.Statsd.Tags))
+
+	// Callback
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Callback.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Callback.URL)
+	assert.Equal(suite.T(), 1.0, suite.ConfGorushDefault.Callback.SampleRate)
+	assert.Equal(suite.T(), 100, suite.ConfGorushDefault.Callback.BatchSize)
+	assert.Equal(suite.T(), 5, suite.ConfGorushDefault.Callback.FlushInterval)
+
+	// Inbound
+	assert.Equal(suite.T(), false, suite.ConfGorushDefault.Inbound.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorushDefault.Inbound.Secret)
+	assert.Equal(suite.T(), 0, suite.ConfGorushDefault.Inbound.Platform)
+	assert.Equal(suite.T(), "tokens", suite.ConfGorushDefault.Inbound.Mapping["tokens"])
+	assert.Equal(suite.T(), "message", suite.ConfGorushDefault.Inbound.Mapping["message"])
 }
 
 func (suite *ConfigTestSuite) TestValidateConf() {
@@ -110,19 +327,62 @@ func (suite *ConfigTestSuite) TestValidateConf() {
 	assert.Equal(suite.T(), "8088", suite.ConfGorush.Core.Port)
 	assert.Equal(suite.T(), int64(8), suite.ConfGorush.Core.WorkerNum)
 	assert.Equal(suite.T(), int64(8192), suite.ConfGorush.Core.QueueNum)
+	assert.Equal(suite.T(), "channel", suite.ConfGorush.Core.QueueEngine)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Core.FastLaneTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.StreamPush)
+	assert.Equal(suite.T(), "gorush-queue.json", suite.ConfGorush.Core.QueueExportPath)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Core.QueueAgeAlert)
+	assert.Equal(suite.T(), 30, suite.ConfGorush.Core.DrainTimeout)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.ValidateToken)
 	assert.Equal(suite.T(), "release", suite.ConfGorush.Core.Mode)
 	assert.Equal(suite.T(), false, suite.ConfGorush.Core.SSL)
 	assert.Equal(suite.T(), "cert.pem", suite.ConfGorush.Core.CertPath)
 	assert.Equal(suite.T(), "key.pem", suite.ConfGorush.Core.KeyPath)
 	assert.Equal(suite.T(), int64(100), suite.ConfGorush.Core.MaxNotification)
+	assert.Equal(suite.T(), int64(0), suite.ConfGorush.Core.MaxBodySize)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Core.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Core.MaxDataSize)
 	assert.Equal(suite.T(), "", suite.ConfGorush.Core.HTTPProxy)
 	// Pid
 	assert.Equal(suite.T(), false, suite.ConfGorush.Core.PID.Enabled)
 	assert.Equal(suite.T(), "gorush.pid", suite.ConfGorush.Core.PID.Path)
 	assert.Equal(suite.T(), true, suite.ConfGorush.Core.PID.Override)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.Outage.Enabled)
+	assert.Equal(suite.T(), 50, suite.ConfGorush.Core.Outage.Threshold)
+	assert.Equal(suite.T(), 30, suite.ConfGorush.Core.Outage.ProbeInterval)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.Throttle.Enabled)
+	assert.Equal(suite.T(), 1, suite.ConfGorush.Core.Throttle.MinConcurrency)
+	assert.Equal(suite.T(), 1, suite.ConfGorush.Core.Throttle.Backoff)
+	assert.Equal(suite.T(), 60, suite.ConfGorush.Core.Throttle.MaxBackoff)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.Chaos.Enabled)
+	assert.Equal(suite.T(), float64(0), suite.ConfGorush.Core.Chaos.DropRate)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Core.Chaos.Delay)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Core.Chaos.ApnsReason)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.Preflight.Enabled)
+	assert.Equal(suite.T(), "warn", suite.ConfGorush.Core.Preflight.Action)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorush.Core.Access.Allow)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorush.Core.Access.Deny)
+	assert.Equal(suite.T(), "none", suite.ConfGorush.Core.Auth.Engine)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Core.Auth.Keys))
+	assert.Equal(suite.T(), "", suite.ConfGorush.Core.Auth.JWTSecret)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Core.Auth.JWTIssuer)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Core.Rejected.Enabled)
+	assert.Equal(suite.T(), 20, suite.ConfGorush.Core.Rejected.Size)
+	assert.Equal(suite.T(), 4096, suite.ConfGorush.Core.Rejected.MaxBody)
 
 	// Api
 	assert.Equal(suite.T(), "/api/push", suite.ConfGorush.API.PushURI)
+	assert.Equal(suite.T(), "/api/push/single", suite.ConfGorush.API.SinglePushURI)
+	assert.Equal(suite.T(), "/api/preview", suite.ConfGorush.API.PreviewURI)
+	assert.Equal(suite.T(), "/api/maintenance", suite.ConfGorush.API.MaintenanceURI)
+	assert.Equal(suite.T(), "/api/app", suite.ConfGorush.API.AppURI)
+	assert.Equal(suite.T(), "/api/queue/export", suite.ConfGorush.API.QueueExportURI)
+	assert.Equal(suite.T(), "/api/queue/import", suite.ConfGorush.API.QueueImportURI)
+	assert.Equal(suite.T(), "/api/inbound", suite.ConfGorush.API.InboundURI)
+	assert.Equal(suite.T(), "/api/stat/summary", suite.ConfGorush.API.SummaryURI)
+	assert.Equal(suite.T(), "/api/events", suite.ConfGorush.API.EventsURI)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorush.API.EventsOrigins)
+	assert.Equal(suite.T(), "/api/push/rejected", suite.ConfGorush.API.RejectedURI)
 	assert.Equal(suite.T(), "/api/stat/go", suite.ConfGorush.API.StatGoURI)
 	assert.Equal(suite.T(), "/api/stat/app", suite.ConfGorush.API.StatAppURI)
 	assert.Equal(suite.T(), "/api/config", suite.ConfGorush.API.ConfigURI)
@@ -131,20 +391,101 @@ func (suite *ConfigTestSuite) TestValidateConf() {
 	// Android
 	assert.Equal(suite.T(), true, suite.ConfGorush.Android.Enabled)
 	assert.Equal(suite.T(), "YOUR_API_KEY", suite.ConfGorush.Android.APIKey)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.PackageName)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Android.EnforcePackage)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.ProxyCert)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.ProxyKey)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.FallbackProxy)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Android.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Android.MaxDataSize)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.Log.Path)
+	assert.Equal(suite.T(), "info", suite.ConfGorush.Android.Log.Level)
+	assert.Equal(suite.T(), "string", suite.ConfGorush.Android.Log.Format)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Android.Encrypt.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.Encrypt.KeyID)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.Encrypt.Key)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Android.Batch.Enabled)
+	assert.Equal(suite.T(), 100, suite.ConfGorush.Android.Batch.Window)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Android.Quiet.Enabled)
+	assert.Equal(suite.T(), "22:00", suite.ConfGorush.Android.Quiet.Start)
+	assert.Equal(suite.T(), "07:00", suite.ConfGorush.Android.Quiet.End)
+	assert.Equal(suite.T(), "UTC", suite.ConfGorush.Android.Quiet.Timezone)
+	assert.Equal(suite.T(), "hold", suite.ConfGorush.Android.Quiet.Action)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Android.HTTP.MaxIdleConns)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Android.HTTP.IdleConnTimeout)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Android.HTTP.TLSSessionCache)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.HTTP.RootCA)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorush.Android.TestTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Android.TrimPayload)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.DataSchema)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Android.HashSalt)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Android.Transform.Strip))
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Android.Transform.Rename))
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Android.Transform.Inject))
 
 	// iOS
 	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.Enabled)
 	assert.Equal(suite.T(), "key.pem", suite.ConfGorush.Ios.KeyPath)
 	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.Password)
 	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.Production)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.Proxy)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.FallbackProxy)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.LazyInit)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Ios.MaxTokens)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Ios.MaxDataSize)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Ios.Certs))
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.Log.Path)
+	assert.Equal(suite.T(), "info", suite.ConfGorush.Ios.Log.Level)
+	assert.Equal(suite.T(), "string", suite.ConfGorush.Ios.Log.Format)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.Encrypt.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.Encrypt.KeyID)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.Encrypt.Key)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.Quiet.Enabled)
+	assert.Equal(suite.T(), "22:00", suite.ConfGorush.Ios.Quiet.Start)
+	assert.Equal(suite.T(), "07:00", suite.ConfGorush.Ios.Quiet.End)
+	assert.Equal(suite.T(), "UTC", suite.ConfGorush.Ios.Quiet.Timezone)
+	assert.Equal(suite.T(), "hold", suite.ConfGorush.Ios.Quiet.Action)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Ios.HTTP.MaxIdleConns)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Ios.HTTP.IdleConnTimeout)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Ios.HTTP.TLSSessionCache)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.HTTP.RootCA)
+	assert.Equal(suite.T(), []string{}, suite.ConfGorush.Ios.TestTokens)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Ios.TrimPayload)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.DataSchema)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ios.HashSalt)
+
+	// ntfy
+	assert.Equal(suite.T(), false, suite.ConfGorush.Ntfy.Enabled)
+	assert.Equal(suite.T(), "https://ntfy.sh", suite.ConfGorush.Ntfy.ServerURL)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Ntfy.Token)
+
+	// Gotify
+	assert.Equal(suite.T(), false, suite.ConfGorush.Gotify.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Gotify.ServerURL)
+
+	// Telegram
+	assert.Equal(suite.T(), false, suite.ConfGorush.Telegram.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Telegram.BotToken)
+	assert.Equal(suite.T(), "https://api.telegram.org", suite.ConfGorush.Telegram.APIURL)
+
+	// Slack
+	assert.Equal(suite.T(), false, suite.ConfGorush.Slack.Enabled)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Slack.Webhooks))
+
+	// Microsoft Teams
+	assert.Equal(suite.T(), false, suite.ConfGorush.Teams.Enabled)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Teams.Webhooks))
 
 	// log
+	assert.Equal(suite.T(), "logrus", suite.ConfGorush.Log.Engine)
 	assert.Equal(suite.T(), "string", suite.ConfGorush.Log.Format)
 	assert.Equal(suite.T(), "stdout", suite.ConfGorush.Log.AccessLog)
 	assert.Equal(suite.T(), "debug", suite.ConfGorush.Log.AccessLevel)
 	assert.Equal(suite.T(), "stderr", suite.ConfGorush.Log.ErrorLog)
 	assert.Equal(suite.T(), "error", suite.ConfGorush.Log.ErrorLevel)
 	assert.Equal(suite.T(), true, suite.ConfGorush.Log.HideToken)
+	assert.Equal(suite.T(), false, suite.ConfGorush.Log.HashToken)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Log.HashSalt)
 
 	assert.Equal(suite.T(), "memory", suite.ConfGorush.Stat.Engine)
 	assert.Equal(suite.T(), "localhost:6379", suite.ConfGorush.Stat.Redis.Addr)
@@ -156,6 +497,25 @@ func (suite *ConfigTestSuite) TestValidateConf() {
 
 	assert.Equal(suite.T(), "bunt.db", suite.ConfGorush.Stat.BuntDB.Path)
 	assert.Equal(suite.T(), "level.db", suite.ConfGorush.Stat.LevelDB.Path)
+
+	assert.Equal(suite.T(), false, suite.ConfGorush.Stat.Statsd.Enabled)
+	assert.Equal(suite.T(), "localhost:8125", suite.ConfGorush.Stat.Statsd.Addr)
+	assert.Equal(suite.T(), "gorush", suite.ConfGorush.Stat.Statsd.Prefix)
+	assert.Equal(suite.T(), 0, len(suite.ConfGorush.Stat.Statsd.Tags))
+
+	// Callback
+	assert.Equal(suite.T(), false, suite.ConfGorush.Callback.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Callback.URL)
+	assert.Equal(suite.T(), 1.0, suite.ConfGorush.Callback.SampleRate)
+	assert.Equal(suite.T(), 100, suite.ConfGorush.Callback.BatchSize)
+	assert.Equal(suite.T(), 5, suite.ConfGorush.Callback.FlushInterval)
+
+	// Inbound
+	assert.Equal(suite.T(), false, suite.ConfGorush.Inbound.Enabled)
+	assert.Equal(suite.T(), "", suite.ConfGorush.Inbound.Secret)
+	assert.Equal(suite.T(), 0, suite.ConfGorush.Inbound.Platform)
+	assert.Equal(suite.T(), "tokens", suite.ConfGorush.Inbound.Mapping["tokens"])
+	assert.Equal(suite.T(), "message", suite.ConfGorush.Inbound.Mapping["message"])
 }
 
 func TestConfigTestSuite(t *testing.T) {
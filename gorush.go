@@ -1,17 +1,317 @@
 package main
 
 import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/appleboy/gorush/client"
 	"github.com/appleboy/gorush/config"
 	"github.com/appleboy/gorush/gorush"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
-func checkInput(token, message string) {
-	if len(token) == 0 {
+// batchTokens is max tokens per push in command line mode.
+const batchTokens = 1000
+
+// stringSlice is repeatable string flag.
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseData convert key=value pairs or JSON object into notification data.
+func parseData(values []string) (gorush.D, error) {
+	data := gorush.D{}
+
+	for _, value := range values {
+		if strings.HasPrefix(strings.TrimSpace(value), "{") {
+			if err := json.Unmarshal([]byte(value), &data); err != nil {
+				return nil, fmt.Errorf("Can't parse data %s: %v", value, err)
+			}
+			continue
+		}
+
+		pair := strings.SplitN(value, "=", 2)
+		if len(pair) != 2 || pair[0] == "" {
+			return nil, fmt.Errorf("Wrong data format %s, must be key=value or JSON object", value)
+		}
+
+		data[pair[0]] = pair[1]
+	}
+
+	return data, nil
+}
+
+// splitTopics group tokens of topic:token format by topic, topics are in order of
+// their first token.
+func splitTopics(tokens []string) ([]string, map[string][]string, error) {
+	var topics []string
+	result := map[string][]string{}
+
+	for _, token := range tokens {
+		pair := strings.SplitN(token, ":", 2)
+		if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return nil, nil, fmt.Errorf("Wrong token format %s, must be topic:token", token)
+		}
+
+		if _, ok := result[pair[0]]; !ok {
+			topics = append(topics, pair[0])
+		}
+
+		result[pair[0]] = append(result[pair[0]], pair[1])
+	}
+
+	return topics, result, nil
+}
+
+// readTokens load device tokens from file, one token per line (supports gz).
+func readTokens(path string) ([]string, error) {
+	var reader io.Reader
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	reader = file
+	if filepath.Ext(path) == ".gz" {
+		gz, err := gzip.NewReader(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer gz.Close()
+		reader = gz
+	}
+
+	var tokens []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		token := strings.TrimSpace(scanner.Text())
+		if token == "" || strings.HasPrefix(token, "#") {
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, scanner.Err()
+}
+
+// pushCount return success and failure counts of platform.
+func pushCount(platform int) (int64, int64) {
+	if platform == gorush.PlatFormIos {
+		return gorush.StatStorage.GetIosSuccess(), gorush.StatStorage.GetIosError()
+	}
+
+	return gorush.StatStorage.GetAndroidSuccess(), gorush.StatStorage.GetAndroidError()
+}
+
+// sendNotification push notification in batches and print the summary.
+func sendNotification(req gorush.PushNotification) bool {
+	var success, failure int64
+
+	checkInput(req.Tokens, req.Message)
+
+	tokens := req.Tokens
+	total := len(tokens)
+	for start := 0; start < total; start += batchTokens {
+		end := start + batchTokens
+		if end > total {
+			end = total
+		}
+
+		req.Tokens = tokens[start:end]
+
+		if err := gorush.CheckMessage(req); err != nil {
+			gorush.LogError.Fatal(err)
+		}
+
+		beforeSuccess, beforeFailure := pushCount(req.Platform)
+
+		switch req.Platform {
+		case gorush.PlatFormIos:
+			gorush.PushToIOS(req)
+		case gorush.PlatFormAndroid:
+			gorush.PushToAndroid(req)
+		}
+
+		afterSuccess, afterFailure := pushCount(req.Platform)
+		success += afterSuccess - beforeSuccess
+		failure += afterFailure - beforeFailure
+
+		if total > batchTokens {
+			fmt.Printf("Progress: %d/%d\n", end, total)
+		}
+	}
+
+	fmt.Printf("Total: %d, Success: %d, Failure: %d\n", total, success, failure)
+
+	return failure == 0
+}
+
+// newClient create api client of gorush server, api key is sent as bearer token.
+func newClient(server, apiKey string) *client.Client {
+	c := client.New(server)
+	c.APIKey = apiKey
+
+	return c
+}
+
+// sendRemote push notification in batches through running gorush server.
+func sendRemote(c *client.Client, req gorush.PushNotification) bool {
+	var queued int
+
+	checkInput(req.Tokens, req.Message)
+
+	tokens := req.Tokens
+	total := len(tokens)
+	for start := 0; start < total; start += batchTokens {
+		end := start + batchTokens
+		if end > total {
+			end = total
+		}
+
+		req.Tokens = tokens[start:end]
+
+		if err := c.Send(req); err != nil {
+			gorush.LogError.Error("Send notification to server error: ", err)
+			break
+		}
+
+		queued = end
+	}
+
+	fmt.Printf("Total: %d, Queued: %d\n", total, queued)
+
+	return queued == total
+}
+
+// runBench push notifications to mock provider with workers of config and print the stats.
+func runBench(args []string) error {
+	var opts gorush.BenchOptions
+	var delay int
+
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	flags.IntVar(&opts.Notifications, "n", 10000, "number of notifications")
+	flags.IntVar(&opts.Tokens, "tokens", 1, "tokens of each notification")
+	flags.IntVar(&opts.Rate, "rate", 0, "notifications per second, 0 is unlimited")
+	flags.IntVar(&delay, "delay", 0, "response time of mock provider in milliseconds")
+	flags.Parse(args)
+	opts.Delay = time.Duration(delay) * time.Millisecond
+
+	gorush.InitAppStatus()
+	gorush.InitWorkers(int64(gorush.PushConf.Core.WorkerNum), int64(gorush.PushConf.Core.QueueNum))
+
+	result, err := gorush.RunBench(opts)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pushes: %d, Errors: %d, Duration: %s\n", result.Pushes, result.Errors, result.Duration)
+	fmt.Printf("Throughput: %.2f pushes/s\n", result.Throughput)
+	fmt.Printf("Latency: p50 %s, p99 %s, max %s\n", result.LatencyP50, result.LatencyP99, result.LatencyMax)
+	fmt.Printf("Allocs: %d allocs/push, %d B/push\n", result.AllocsPerPush, result.BytesPerPush)
+
+	return nil
+}
+
+// runQueue export pending notifications of server into file or import them back.
+func runQueue(c *client.Client, action, path string) error {
+	if path == "" {
+		return errors.New("Missing file of queue command")
+	}
+
+	switch action {
+	case "export":
+		// create file before dequeuing, so notifications are not lost.
+		file, err := os.Create(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		notifications, err := c.ExportQueue()
+
+		if err != nil {
+			return fmt.Errorf("Export queue error: %v", err)
+		}
+
+		if err := gorush.WriteSnapshot(file, notifications); err != nil {
+			return err
+		}
+
+		fmt.Printf("Exported: %d\n", len(notifications))
+	case "import":
+		file, err := os.Open(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		notifications, err := gorush.ReadSnapshot(file)
+
+		if err != nil {
+			return err
+		}
+
+		result, err := c.ImportQueue(notifications)
+
+		if err != nil {
+			return fmt.Errorf("Import queue error: %v", err)
+		}
+
+		fmt.Printf("Imported: %d, Queue: %d\n", result.Imported, result.QueueUsage)
+
+		if result.Remaining > 0 {
+			// keep notifications not imported, so the file can be imported again later.
+			rest, err := os.Create(path)
+
+			if err != nil {
+				return err
+			}
+
+			defer rest.Close()
+
+			if err := gorush.WriteSnapshot(rest, notifications[result.Imported:]); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("Queue is full, %d notifications are kept in %s", result.Remaining, path)
+		}
+	default:
+		return fmt.Errorf("Wrong queue command %s, must be export or import", action)
+	}
+
+	return nil
+}
+
+func checkInput(tokens []string, message string) {
+	if len(tokens) == 0 {
 		gorush.LogError.Fatal("Missing token flag (-t)")
 	}
 
@@ -30,12 +330,30 @@ Server Options:
     -p, --port <port>                Use port for clients (default: 8088)
     -c, --config <file>              Configuration file
     -m, --message <message>          Notification message
-    -t, --token <token>              Notification token
+    -t, --token <token>              Notification token (repeatable)
+    --tokens-file <file>             Notification tokens file, one token per line (supports gz)
+    --title <title>                  Notification title
+    --sound <sound>                  Notification sound
+    --priority <priority>            Notification priority (normal, high, 1, 5 or 10)
+    --data <key=value>               Notification data, key=value or JSON object (repeatable)
     --proxy <proxy>                  Proxy URL (only for GCM)
+    --server <url>                   Send through running gorush server, e.g. http://localhost:8088
+    --api-key <key>                  API key of gorush server sent as bearer token (requires --server)
+    --maintenance <on|off>           Set maintenance mode of gorush server (requires --server)
+    --export                         Export undelivered notifications in maintenance mode
+Bench Command:
+    bench [-n <num>] [-tokens <num>] [-rate <num>] [-delay <ms>]
+                                     Push notifications to local mock provider and report
+                                     throughput, latency and allocations of queue and workers
+Queue Commands (requires --server):
+    queue export <file>              Export pending notifications in maintenance mode into file
+    queue import <file>              Import notifications of file into queue
 iOS Options:
     -i, --key <file>                 certificate key file path
     -P, --password <password>        certificate key password
     --topic <topic>                  iOS topic
+    --topic-per-token                iOS tokens are topic:token, notifications are sent by topic
+    --badge <badge>                  iOS badge count
     --ios                            enabled iOS (default: false)
     --production                     iOS production mode (default: false)
 Android Options:
@@ -80,9 +398,20 @@ func main() {
 	var showVersion bool
 	var configFile string
 	var topic string
+	var topicPerToken bool
 	var message string
-	var token string
+	var tokens stringSlice
+	var tokensFile string
 	var proxy string
+	var server string
+	var apiKey string
+	var maintenance string
+	var export bool
+	var title string
+	var sound string
+	var priority string
+	var badge int
+	var data stringSlice
 
 	flag.BoolVar(&showVersion, "version", false, "Print version information.")
 	flag.BoolVar(&showVersion, "v", false, "Print version information.")
@@ -96,15 +425,26 @@ func main() {
 	flag.StringVar(&opts.Android.APIKey, "apikey", "", "Android api key configuration for gorush")
 	flag.StringVar(&opts.Core.Port, "p", "", "port number for gorush")
 	flag.StringVar(&opts.Core.Port, "port", "", "port number for gorush")
-	flag.StringVar(&token, "t", "", "token string")
-	flag.StringVar(&token, "token", "", "token string")
+	flag.Var(&tokens, "t", "token string")
+	flag.Var(&tokens, "token", "token string")
+	flag.StringVar(&tokensFile, "tokens-file", "", "tokens file path")
 	flag.StringVar(&message, "m", "", "notification message")
 	flag.StringVar(&message, "message", "", "notification message")
 	flag.BoolVar(&opts.Android.Enabled, "android", false, "send android notification")
 	flag.BoolVar(&opts.Ios.Enabled, "ios", false, "send ios notification")
 	flag.BoolVar(&opts.Ios.Production, "production", false, "production mode in iOS")
 	flag.StringVar(&topic, "topic", "", "apns topic in iOS")
+	flag.BoolVar(&topicPerToken, "topic-per-token", false, "tokens with apns topic in iOS")
 	flag.StringVar(&proxy, "proxy", "", "http proxy url")
+	flag.StringVar(&server, "server", "", "gorush server url")
+	flag.StringVar(&apiKey, "api-key", "", "api key of gorush server")
+	flag.StringVar(&maintenance, "maintenance", "", "maintenance mode of gorush server")
+	flag.BoolVar(&export, "export", false, "export undelivered notifications")
+	flag.StringVar(&title, "title", "", "notification title")
+	flag.StringVar(&sound, "sound", "", "notification sound")
+	flag.StringVar(&priority, "priority", "", "notification priority")
+	flag.IntVar(&badge, "badge", 0, "badge count in iOS")
+	flag.Var(&data, "data", "notification data")
 
 	flag.Usage = usage
 	flag.Parse()
@@ -154,6 +494,11 @@ func main() {
 		gorush.PushConf.Core.Port = opts.Core.Port
 	}
 
+	if flag.Arg(0) == "bench" {
+		// access and push logs of bench are discarded unless they are errors.
+		gorush.PushConf.Log.AccessLevel = "error"
+	}
+
 	if err = gorush.InitLog(); err != nil {
 		log.Println(err)
 
@@ -175,23 +520,88 @@ func main() {
 		}
 	}
 
+	if maintenance != "" {
+		if server == "" {
+			gorush.LogError.Fatal("Missing server flag (--server)")
+		}
+
+		if maintenance != "on" && maintenance != "off" {
+			gorush.LogError.Fatal("Wrong maintenance flag, must be on or off")
+		}
+
+		result, err := newClient(server, apiKey).Maintenance(maintenance == "on", export)
+
+		if err != nil {
+			gorush.LogError.Fatal("Set maintenance error: ", err)
+		}
+
+		fmt.Printf("Maintenance: %t, Queue: %d, Exported: %d\n", result.Maintenance, result.QueueUsage, result.Exported)
+
+		return
+	}
+
+	if flag.Arg(0) == "bench" {
+		if err = runBench(flag.Args()[1:]); err != nil {
+			gorush.LogError.Fatal(err)
+		}
+
+		return
+	}
+
+	if flag.Arg(0) == "queue" {
+		if server == "" {
+			gorush.LogError.Fatal("Missing server flag (--server)")
+		}
+
+		if err = runQueue(newClient(server, apiKey), flag.Arg(1), flag.Arg(2)); err != nil {
+			gorush.LogError.Fatal(err)
+		}
+
+		return
+	}
+
+	if err = gorush.InitGCMTransport(); err != nil {
+		gorush.LogError.Fatal("Set Android http config error: ", err)
+	}
+
+	if tokensFile != "" {
+		fileTokens, err := readTokens(tokensFile)
+
+		if err != nil {
+			gorush.LogError.Fatal("Read tokens file error: ", err)
+		}
+
+		tokens = append(tokens, fileTokens...)
+	}
+
 	// send android notification
 	if opts.Android.Enabled {
 		gorush.PushConf.Android.Enabled = opts.Android.Enabled
 		req := gorush.PushNotification{
-			Tokens:   []string{token},
+			Tokens:   tokens,
 			Platform: gorush.PlatFormAndroid,
 			Message:  message,
+			Title:    title,
+			Sound:    sound,
+			Priority: gorush.Priority(priority),
 		}
 
-		err := gorush.CheckMessage(req)
-
-		if err != nil {
+		if req.Data, err = parseData(data); err != nil {
 			gorush.LogError.Fatal(err)
 		}
 
+		if server != "" {
+			if !sendRemote(newClient(server, apiKey), req) {
+				os.Exit(1)
+			}
+
+			return
+		}
+
 		gorush.InitAppStatus()
-		gorush.PushToAndroid(req)
+		if !sendNotification(req) {
+			os.Exit(1)
+		}
 
 		return
 	}
@@ -204,24 +614,64 @@ func main() {
 
 		gorush.PushConf.Ios.Enabled = opts.Ios.Enabled
 		req := gorush.PushNotification{
-			Tokens:   []string{token},
+			Tokens:   tokens,
 			Platform: gorush.PlatFormIos,
 			Message:  message,
+			Title:    title,
+			Sound:    sound,
+			Priority: gorush.Priority(priority),
+			Badge:    badge,
 		}
 
 		if topic != "" {
 			req.Topic = topic
 		}
 
-		err := gorush.CheckMessage(req)
-
-		if err != nil {
+		if req.Data, err = parseData(data); err != nil {
 			gorush.LogError.Fatal(err)
 		}
 
-		gorush.InitAppStatus()
-		gorush.InitAPNSClient()
-		gorush.PushToIOS(req)
+		reqs := []gorush.PushNotification{req}
+		if topicPerToken {
+			topics, topicTokens, err := splitTopics(tokens)
+
+			if err != nil {
+				gorush.LogError.Fatal(err)
+			}
+
+			reqs = nil
+			for _, topic := range topics {
+				topicReq := req
+				topicReq.Topic = topic
+				topicReq.Tokens = topicTokens[topic]
+				reqs = append(reqs, topicReq)
+			}
+		}
+
+		var c *client.Client
+		if server != "" {
+			c = newClient(server, apiKey)
+		} else {
+			gorush.InitAppStatus()
+			gorush.InitAPNSClient()
+		}
+
+		success := true
+		for _, req := range reqs {
+			if len(reqs) > 1 {
+				fmt.Printf("Topic: %s\n", req.Topic)
+			}
+
+			if c != nil {
+				success = sendRemote(c, req) && success
+			} else {
+				success = sendNotification(req) && success
+			}
+		}
+
+		if !success {
+			os.Exit(1)
+		}
 
 		return
 	}
@@ -236,6 +686,43 @@ func main() {
 
 	gorush.InitAppStatus()
 	gorush.InitAPNSClient()
+	gorush.InitApps()
+
+	if err = gorush.InitSchemas(); err != nil {
+		gorush.LogError.Fatal(err)
+	}
+
+	if err = gorush.Preflight(); err != nil {
+		gorush.LogError.Fatal(err)
+	}
+
+	gorush.InitCallback()
 	gorush.InitWorkers(int64(gorush.PushConf.Core.WorkerNum), int64(gorush.PushConf.Core.QueueNum))
-	gorush.RunHTTPServer()
+
+	// queue notifications exported by last exit, on restart old process
+	// exports its queue after new process is serving.
+	go func() {
+		gorush.WaitHandover()
+
+		if count, err := gorush.ImportQueue(gorush.PushConf.Core.QueueExportPath); err != nil {
+			gorush.LogError.Error("Can't import queue after ", count, " notifications: ", err)
+		} else if count > 0 {
+			gorush.LogAccess.Info("import ", count, " notifications from ", gorush.PushConf.Core.QueueExportPath)
+		}
+	}()
+
+	if err = gorush.RunHTTPServer(); err != nil {
+		gorush.LogError.Error(err)
+	}
+
+	// deliver queued notifications before exit, new process takes over the listener on SIGHUP.
+	count, err := gorush.DrainQueue(time.Duration(gorush.PushConf.Core.DrainTimeout) * time.Second)
+	gorush.EndHandover()
+	if err != nil {
+		gorush.LogError.Fatal("Can't export queue: ", err)
+	}
+
+	if count > 0 {
+		gorush.LogAccess.Info("export ", count, " notifications to ", gorush.PushConf.Core.QueueExportPath)
+	}
 }
@@ -0,0 +1,86 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// parseCIDRs parse CIDR list, plain IP is treated as single address network.
+func parseCIDRs(list []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(list))
+	for _, value := range list {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %s", value)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP return IP of connection peer, X-Forwarded-For header is not trusted.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+// AccessMiddleware reject request of client IP in deny list or not in allow list
+// before request body is parsed. Empty allow list allows all IPs.
+func AccessMiddleware() gin.HandlerFunc {
+	// CIDRs are checked by CheckPushConf on startup.
+	allow, _ := parseCIDRs(PushConf.Core.Access.Allow)
+	deny, _ := parseCIDRs(PushConf.Core.Access.Deny)
+
+	return func(c *gin.Context) {
+		if len(allow) == 0 && len(deny) == 0 {
+			c.Next()
+			return
+		}
+
+		ip := remoteIP(c.Request)
+		if ip == nil || containsIP(deny, ip) || (len(allow) > 0 && !containsIP(allow, ip)) {
+			msg := "Access denied for " + c.Request.RemoteAddr
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusForbidden, "Access denied.")
+			return
+		}
+
+		c.Next()
+	}
+}
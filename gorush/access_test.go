@@ -0,0 +1,66 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/appleboy/gofight.v1"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	networks, err := parseCIDRs([]string{"10.0.0.0/8", "192.168.1.10", "::1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, networks, 3)
+	assert.True(t, containsIP(networks, net.ParseIP("10.1.2.3")))
+	assert.True(t, containsIP(networks, net.ParseIP("192.168.1.10")))
+	assert.True(t, containsIP(networks, net.ParseIP("::1")))
+	assert.False(t, containsIP(networks, net.ParseIP("192.168.1.11")))
+
+	_, err = parseCIDRs([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = parseCIDRs([]string{"localhost"})
+	assert.Error(t, err)
+}
+
+func TestRemoteIP(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:1234"
+	req.Header.Set("X-Forwarded-For", "192.168.1.10")
+
+	assert.Equal(t, "10.1.2.3", remoteIP(req).String())
+}
+
+func TestWrongAccessConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+	PushConf.Core.Access.Allow = []string{"10.0.0.0/33"}
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Wrong access allow list")
+}
+
+func TestAccessMiddleware(t *testing.T) {
+	initTest()
+	InitLog()
+
+	PushConf.Core.Access.Allow = []string{"10.0.0.0/8"}
+
+	r := gofight.New()
+
+	r.GET("/api/stat/go").
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Access denied.", value)
+			assert.Equal(t, http.StatusForbidden, r.Code)
+		})
+}
@@ -0,0 +1,603 @@
+//go:build !noapns
+// +build !noapns
+
+package gorush
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
+	apns "github.com/sideshow/apns2"
+	"github.com/sideshow/apns2/certificate"
+	"github.com/sideshow/apns2/payload"
+	"golang.org/x/net/http2"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// hasAPNs is false if built with noapns tag.
+const hasAPNs = true
+
+var (
+	// ApnsClient is apns client
+	ApnsClient *apns.Client
+	// ApnsClients is apns client of extra certificates by topic
+	ApnsClients map[string]*apns.Client
+	// apnsProxy is egress proxy of apns clients, fallback proxy after failover.
+	apnsProxy string
+	// apnsLock protect apns clients, which are swapped on failover.
+	apnsLock sync.RWMutex
+)
+
+func loadIosCertificate(keyPath, password string) (tls.Certificate, error) {
+	switch filepath.Ext(keyPath) {
+	case ".p12":
+		return certificate.FromP12File(keyPath, password)
+	case ".pem":
+		return certificate.FromPemFile(keyPath, password)
+	default:
+		return tls.Certificate{}, errors.New("Wrong Certificate key extension.")
+	}
+}
+
+// newApnsHTTPClient create HTTP/2 client of APNs, through proxy if not empty.
+func newApnsHTTPClient(cert tls.Certificate, proxy string) (*http.Client, error) {
+	transport := &http.Transport{}
+
+	if proxy != "" {
+		var err error
+		if transport, err = newProxyTransport(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if len(cert.Certificate) > 0 {
+		transport.TLSClientConfig.BuildNameToCertificate()
+	}
+
+	if err := tuneTransport(transport, PushConf.Ios.HTTP); err != nil {
+		return nil, err
+	}
+
+	if err := http2.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   apns.HTTPClientTimeout,
+	}, nil
+}
+
+func newApnsClient(cert tls.Certificate, proxy string) (*apns.Client, error) {
+	client := apns.NewClient(cert)
+
+	if proxy != "" || PushConf.Ios.HTTP != (config.SectionHTTP{}) {
+		httpClient, err := newApnsHTTPClient(cert, proxy)
+
+		if err != nil {
+			return nil, err
+		}
+
+		client.HTTPClient = httpClient
+
+		if proxy != "" {
+			LogAccess.Debug("Set APNs proxy as " + proxy)
+		}
+	}
+
+	if client.HTTPClient != nil {
+		client.HTTPClient.Transport = &retryAfterTransport{
+			platform: PlatFormIos,
+			base:     client.HTTPClient.Transport,
+		}
+	}
+
+	if PushConf.Ios.Production {
+		return client.Production(), nil
+	}
+
+	return client.Development(), nil
+}
+
+// InitAPNSClient use for initialize APNs Client.
+// Clients are initialized on first push if lazy_init is enabled.
+func InitAPNSClient() error {
+	return switchAPNSProxy(PushConf.Ios.Proxy)
+}
+
+// switchAPNSProxy create apns clients through proxy, then replace clients used
+// by workers and close connections of old clients.
+func switchAPNSProxy(proxy string) error {
+	if !PushConf.Ios.Enabled {
+		return nil
+	}
+
+	if PushConf.Ios.LazyInit {
+		setAPNSClients(nil, map[string]*apns.Client{}, proxy)
+
+		return nil
+	}
+
+	cert, err := loadIosCertificate(PushConf.Ios.KeyPath, PushConf.Ios.Password)
+
+	if err != nil {
+		LogError.Error("Cert Error:", err.Error())
+
+		return err
+	}
+
+	client, err := newApnsClient(cert, proxy)
+
+	if err != nil {
+		LogError.Error("APNs Proxy Error:", err.Error())
+
+		return err
+	}
+
+	clients := make(map[string]*apns.Client, len(PushConf.Ios.Certs))
+	for _, c := range PushConf.Ios.Certs {
+		cert, err := loadIosCertificate(c.KeyPath, c.Password)
+
+		if err != nil {
+			LogError.Error("Cert Error of topic "+c.Topic+":", err.Error())
+
+			return err
+		}
+
+		if clients[c.Topic], err = newApnsClient(cert, proxy); err != nil {
+			LogError.Error("APNs Proxy Error:", err.Error())
+
+			return err
+		}
+	}
+
+	CertificatePemIos = cert
+	setAPNSClients(client, clients, proxy)
+
+	return nil
+}
+
+// setAPNSClients replace apns clients and close idle connections of old clients,
+// pushes in flight still finish with old clients.
+func setAPNSClients(client *apns.Client, clients map[string]*apns.Client, proxy string) {
+	apnsLock.Lock()
+	oldClient, oldClients := ApnsClient, ApnsClients
+	ApnsClient = client
+	ApnsClients = clients
+	apnsProxy = proxy
+	apnsLock.Unlock()
+
+	closeAPNSClient(oldClient)
+	for _, c := range oldClients {
+		closeAPNSClient(c)
+	}
+}
+
+// closeAPNSClient close idle connections of apns client.
+func closeAPNSClient(client *apns.Client) {
+	if client == nil || client.HTTPClient == nil {
+		return
+	}
+
+	if transport, ok := client.HTTPClient.Transport.(interface {
+		CloseIdleConnections()
+	}); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// lazyAPNSClient return apns client of topic, initialize it on first use.
+func lazyAPNSClient(topic string) *apns.Client {
+	apnsLock.Lock()
+	defer apnsLock.Unlock()
+
+	for _, c := range PushConf.Ios.Certs {
+		if topic == "" || c.Topic != topic {
+			continue
+		}
+
+		if client, ok := ApnsClients[topic]; ok {
+			return client
+		}
+
+		cert, err := loadIosCertificate(c.KeyPath, c.Password)
+
+		if err != nil {
+			LogError.Error("Cert Error of topic "+topic+":", err.Error())
+
+			return nil
+		}
+
+		client, err := newApnsClient(cert, apnsProxy)
+
+		if err != nil {
+			LogError.Error("APNs Proxy Error:", err.Error())
+
+			return nil
+		}
+
+		ApnsClients[topic] = client
+
+		return client
+	}
+
+	if ApnsClient == nil {
+		cert, err := loadIosCertificate(PushConf.Ios.KeyPath, PushConf.Ios.Password)
+
+		if err != nil {
+			LogError.Error("Cert Error:", err.Error())
+
+			return nil
+		}
+
+		if ApnsClient, err = newApnsClient(cert, apnsProxy); err != nil {
+			LogError.Error("APNs Proxy Error:", err.Error())
+
+			return nil
+		}
+	}
+
+	return ApnsClient
+}
+
+// apnsClientForTopic return apns client of topic, default as ApnsClient.
+func apnsClientForTopic(topic string) *apns.Client {
+	if PushConf.Ios.LazyInit {
+		return lazyAPNSClient(topic)
+	}
+
+	apnsLock.RLock()
+	defer apnsLock.RUnlock()
+
+	if client, ok := ApnsClients[topic]; ok && topic != "" {
+		return client
+	}
+
+	return ApnsClient
+}
+
+func iosAlertDictionary(payload *payload.Payload, req PushNotification) *payload.Payload {
+	// Alert dictionary
+
+	if len(req.Title) > 0 {
+		payload.AlertTitle(req.Title)
+	}
+
+	if len(req.Alert.TitleLocKey) > 0 {
+		payload.AlertTitleLocKey(req.Alert.TitleLocKey)
+	}
+
+	if len(req.Alert.LocArgs) > 0 {
+		payload.AlertLocArgs(req.Alert.LocArgs)
+	}
+
+	if len(req.Alert.TitleLocArgs) > 0 {
+		payload.AlertTitleLocArgs(req.Alert.TitleLocArgs)
+	}
+
+	if len(req.Alert.Body) > 0 {
+		payload.AlertBody(req.Alert.Body)
+	}
+
+	if len(req.Alert.LaunchImage) > 0 {
+		payload.AlertLaunchImage(req.Alert.LaunchImage)
+	}
+
+	if len(req.Alert.LocKey) > 0 {
+		payload.AlertLocKey(req.Alert.LocKey)
+	}
+
+	if len(req.Alert.Action) > 0 {
+		payload.AlertAction(req.Alert.Action)
+	}
+
+	if len(req.Alert.ActionLocKey) > 0 {
+		payload.AlertActionLocKey(req.Alert.ActionLocKey)
+	}
+
+	// General
+
+	if len(req.Category) > 0 {
+		payload.Category(req.Category)
+	}
+
+	return payload
+}
+
+// payloadPool reuse buffers of payload encoding.
+var payloadPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// encodePayload serialize payload of notification once, so it is shared by all tokens
+// instead of marshaled on every push.
+func encodePayload(notification *apns.Notification) error {
+	buf := payloadPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer payloadPool.Put(buf)
+
+	if err := json.NewEncoder(buf).Encode(notification.Payload); err != nil {
+		return err
+	}
+
+	// remove newline appended by encoder
+	body := make([]byte, buf.Len()-1)
+	copy(body, buf.Bytes())
+	notification.Payload = body
+
+	return nil
+}
+
+// GetIOSNotification use for define iOS notificaiton.
+// The iOS Notification Payload
+// ref: https://developer.apple.com/library/ios/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/Chapters/TheNotificationPayload.html
+func GetIOSNotification(req PushNotification) *apns.Notification {
+	notification := &apns.Notification{
+		ApnsID: req.ApnsID,
+		Topic:  topicForPushType(req.Topic, req.PushType),
+	}
+
+	if req.Expiration > 0 {
+		notification.Expiration = time.Unix(req.Expiration, 0)
+	}
+
+	if priority := req.Priority.Apns(); priority > 0 {
+		notification.Priority = priority
+	}
+
+	payload := payload.NewPayload().Alert(req.Message)
+
+	if req.Badge > 0 {
+		payload.Badge(req.Badge)
+	}
+
+	if len(req.Sound) > 0 {
+		payload.Sound(req.Sound)
+	}
+
+	if req.ContentAvailable {
+		payload.ContentAvailable()
+	}
+
+	if len(req.URLArgs) > 0 {
+		payload.URLArgs(req.URLArgs)
+	}
+
+	data := TransformData(req.Data, PushConf.Ios.Transform)
+	for k, v := range encryptPayloadData(data, PushConf.Ios.Encrypt) {
+		payload.Custom(k, v)
+	}
+
+	payload = iosAlertDictionary(payload, req)
+
+	notification.Payload = payload
+
+	return notification
+}
+
+// PushToIOS provide send notification to APNs server.
+func PushToIOS(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for iOS")
+
+	var isError bool
+	var providerErrors int
+
+	TrimPayload(&req)
+	notification := GetIOSNotification(req)
+	client := apnsClientForTopic(req.Topic)
+
+	if err := encodePayload(notification); err != nil {
+		LogError.Error("Can't encode iOS payload: ", err)
+	}
+
+	if client == nil {
+		for _, token := range req.Tokens {
+			LogPush(FailedPush, token, req, errors.New("APNs client is not initialized"))
+		}
+		StatStorage.AddIosError(int64(len(req.Tokens)))
+
+		return true
+	}
+
+	app := appOf(req)
+	throttle := acquireThrottle(PlatFormIos, app)
+	defer func() {
+		throttle.release(false)
+	}()
+
+	for _, token := range req.Tokens {
+		notification.DeviceToken = token
+
+		// apns-id must be unique for each device token.
+		if req.ApnsID == "" {
+			notification.ApnsID = NewUUID()
+		}
+
+		result := req
+		result.ApnsID = notification.ApnsID
+
+		if err := chaosError(PlatFormIos); err != nil {
+			LogPush(FailedPush, token, result, err)
+			isError = true
+			StatStorage.AddIosError(1)
+			continue
+		}
+
+		// send ios notification
+		res, err := client.Push(notification)
+
+		if err != nil {
+			// apns server error
+			LogPush(FailedPush, token, result, err)
+			isError = true
+			providerErrors++
+			StatStorage.AddIosError(1)
+			continue
+		}
+
+		// record apns-id returned by APNs server
+		if res.ApnsID != "" {
+			result.ApnsID = res.ApnsID
+		}
+
+		if res.StatusCode != 200 {
+			// error message:
+			// ref: https://github.com/sideshow/apns2/blob/master/response.go#L14-L65
+			LogPush(FailedPush, token, result, errors.New(res.Reason))
+			isError = true
+			if res.StatusCode == http.StatusForbidden || res.StatusCode >= http.StatusInternalServerError {
+				providerErrors++
+			}
+			StatStorage.AddIosError(1)
+
+			// back off before sending to the rest tokens.
+			if isThrottledStatus(res.StatusCode) {
+				throttle.release(true)
+				throttle = acquireThrottle(PlatFormIos, app)
+			}
+			continue
+		}
+
+		if res.Sent() {
+			LogPush(SucceededPush, token, result, nil)
+			StatStorage.AddIosSuccess(1)
+		}
+	}
+
+	reportOutage(PlatFormIos, len(req.Tokens) > 0 && providerErrors == len(req.Tokens))
+
+	return isError
+}
+
+// iosPreview return APNs headers and payload of notification.
+func iosPreview(req PushNotification) (map[string]string, []byte, error) {
+	notification := GetIOSNotification(req)
+
+	if err := encodePayload(notification); err != nil {
+		return nil, nil, err
+	}
+
+	headers := map[string]string{}
+	if notification.ApnsID != "" {
+		headers["apns-id"] = notification.ApnsID
+	}
+	if !notification.Expiration.IsZero() {
+		headers["apns-expiration"] = strconv.FormatInt(notification.Expiration.Unix(), 10)
+	}
+	if notification.Priority > 0 {
+		headers["apns-priority"] = strconv.Itoa(notification.Priority)
+	}
+	if notification.Topic != "" {
+		headers["apns-topic"] = notification.Topic
+	}
+
+	return headers, notification.Payload.([]byte), nil
+}
+
+// iosPayloadSize return bytes of APNs payload of notification.
+func iosPayloadSize(req PushNotification) (int, error) {
+	notification := GetIOSNotification(req)
+	if err := encodePayload(notification); err != nil {
+		return 0, err
+	}
+
+	return len(notification.Payload.([]byte)), nil
+}
+
+// preflightIOS probe APNs with invalid token, BadDeviceToken means credentials are accepted.
+func preflightIOS(topic string) error {
+	client := apnsClientForTopic(topic)
+	if client == nil {
+		return errors.New("APNs client is not initialized")
+	}
+
+	res, err := client.Push(&apns.Notification{
+		DeviceToken: preflightToken,
+		Topic:       topic,
+		Payload:     []byte(`{"aps":{}}`),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode == 403 || res.StatusCode >= 500 {
+		return fmt.Errorf("status code %d, reason %s", res.StatusCode, res.Reason)
+	}
+
+	return nil
+}
+
+func pushSingleIOS(req PushNotification) *SingleResponse {
+	result := &SingleResponse{
+		Platform: typeForPlatForm(req.Platform),
+	}
+
+	token := req.Tokens[0]
+	result.Truncated = TrimPayload(&req)
+	notification := GetIOSNotification(req)
+	notification.DeviceToken = token
+
+	if err := encodePayload(notification); err != nil {
+		LogError.Error("Can't encode iOS payload: ", err)
+	}
+
+	if req.ApnsID == "" {
+		notification.ApnsID = NewUUID()
+	}
+	req.ApnsID = notification.ApnsID
+	result.ApnsID = notification.ApnsID
+
+	client := apnsClientForTopic(req.Topic)
+
+	if client == nil {
+		result.Reason = "APNs client is not initialized"
+		LogPush(FailedPush, token, req, errors.New(result.Reason))
+		StatStorage.AddIosError(1)
+		return result
+	}
+
+	start := time.Now()
+	res, err := client.Push(notification)
+	result.Latency = int64(time.Since(start) / time.Millisecond)
+
+	if err != nil {
+		result.Reason = err.Error()
+		LogPush(FailedPush, token, req, err)
+		StatStorage.AddIosError(1)
+		return result
+	}
+
+	if res.ApnsID != "" {
+		req.ApnsID = res.ApnsID
+		result.ApnsID = res.ApnsID
+	}
+
+	result.StatusCode = res.StatusCode
+	result.Reason = res.Reason
+	result.Success = res.Sent()
+
+	if !result.Success {
+		LogPush(FailedPush, token, req, errors.New(res.Reason))
+		StatStorage.AddIosError(1)
+		return result
+	}
+
+	LogPush(SucceededPush, token, req, nil)
+	StatStorage.AddIosSuccess(1)
+
+	return result
+}
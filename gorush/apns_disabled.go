@@ -0,0 +1,61 @@
+//go:build noapns
+// +build noapns
+
+package gorush
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+// hasAPNs is false if built with noapns tag.
+const hasAPNs = false
+
+var errNoAPNs = errors.New("APNs is not built in, rebuild without noapns tag")
+
+func loadIosCertificate(keyPath, password string) (tls.Certificate, error) {
+	return tls.Certificate{}, errNoAPNs
+}
+
+// InitAPNSClient return error if iOS is enabled.
+func InitAPNSClient() error {
+	if PushConf.Ios.Enabled {
+		return errNoAPNs
+	}
+
+	return nil
+}
+
+// switchAPNSProxy return error if iOS is enabled.
+func switchAPNSProxy(proxy string) error {
+	return InitAPNSClient()
+}
+
+// PushToIOS log all tokens as failed.
+func PushToIOS(req PushNotification) bool {
+	for _, token := range req.Tokens {
+		LogPush(FailedPush, token, req, errNoAPNs)
+	}
+	StatStorage.AddIosError(int64(len(req.Tokens)))
+
+	return true
+}
+
+func iosPreview(req PushNotification) (map[string]string, []byte, error) {
+	return nil, nil, errNoAPNs
+}
+
+func iosPayloadSize(req PushNotification) (int, error) {
+	return 0, errNoAPNs
+}
+
+func preflightIOS(topic string) error {
+	return errNoAPNs
+}
+
+func pushSingleIOS(req PushNotification) *SingleResponse {
+	return &SingleResponse{
+		Platform: typeForPlatForm(req.Platform),
+		Reason:   errNoAPNs.Error(),
+	}
+}
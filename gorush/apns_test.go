@@ -0,0 +1,399 @@
+//go:build !noapns
+// +build !noapns
+
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/buger/jsonparser"
+	"github.com/sideshow/apns2"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApnsClientForTopic(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{
+			Topic:    "com.example.app",
+			KeyPath:  "../certificate/certificate-valid.p12",
+			Password: "",
+		},
+	}
+
+	assert.NoError(t, InitAPNSClient())
+	assert.Equal(t, 1, len(ApnsClients))
+
+	assert.Equal(t, ApnsClients["com.example.app"], apnsClientForTopic("com.example.app"))
+	assert.Equal(t, ApnsClient, apnsClientForTopic("com.example.other"))
+	assert.Equal(t, ApnsClient, apnsClientForTopic(""))
+	assert.True(t, ApnsClient != ApnsClients["com.example.app"])
+}
+
+func TestAPNSClientWithProxy(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	PushConf.Ios.Proxy = "http://87.236.233.92:8080"
+
+	assert.NoError(t, InitAPNSClient())
+
+	transport, ok := ApnsClient.HTTPClient.Transport.(*retryAfterTransport).base.(*http.Transport)
+	assert.True(t, ok)
+	assert.NotNil(t, transport.Proxy)
+	assert.Equal(t, 1, len(transport.TLSClientConfig.Certificates))
+
+	PushConf.Ios.Proxy = "a.html"
+	assert.Error(t, InitAPNSClient())
+}
+
+func TestAPNSClientTransportTuning(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	PushConf.Ios.HTTP.MaxIdleConns = 100
+	PushConf.Ios.HTTP.IdleConnTimeout = 90
+	PushConf.Ios.HTTP.TLSSessionCache = 64
+
+	assert.NoError(t, InitAPNSClient())
+
+	transport, ok := ApnsClient.HTTPClient.Transport.(*retryAfterTransport).base.(*http.Transport)
+	assert.True(t, ok)
+	assert.Nil(t, transport.Proxy)
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 100, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	assert.NotNil(t, transport.TLSClientConfig.ClientSessionCache)
+	assert.Equal(t, 1, len(transport.TLSClientConfig.Certificates))
+}
+
+func TestIOSNotificationStructure(t *testing.T) {
+	var dat map[string]interface{}
+	var unix = time.Now().Unix()
+
+	test := "test"
+	message := "Welcome notification Server"
+	req := PushNotification{
+		ApnsID:           test,
+		Topic:            test,
+		Expiration:       time.Now().Unix(),
+		Priority:         "normal",
+		Message:          message,
+		Badge:            1,
+		Sound:            test,
+		ContentAvailable: true,
+		Data: D{
+			"key1": "test",
+			"key2": 2,
+		},
+		Category: test,
+		URLArgs:  []string{"a", "b"},
+	}
+
+	notification := GetIOSNotification(req)
+
+	dump, _ := json.Marshal(notification.Payload)
+	data := []byte(string(dump))
+
+	if err := json.Unmarshal(data, &dat); err != nil {
+		log.Println(err)
+		panic(err)
+	}
+
+	alert, _ := jsonparser.GetString(data, "aps", "alert")
+	badge, _ := jsonparser.GetInt(data, "aps", "badge")
+	sound, _ := jsonparser.GetString(data, "aps", "sound")
+	contentAvailable, _ := jsonparser.GetInt(data, "aps", "content-available")
+	category, _ := jsonparser.GetString(data, "aps", "category")
+	key1 := dat["key1"].(interface{})
+	key2 := dat["key2"].(interface{})
+	aps := dat["aps"].(map[string]interface{})
+	urlArgs := aps["url-args"].([]interface{})
+
+	assert.Equal(t, test, notification.ApnsID)
+	assert.Equal(t, test, notification.Topic)
+	assert.Equal(t, unix, notification.Expiration.Unix())
+	assert.Equal(t, ApnsPriorityLow, notification.Priority)
+	assert.Equal(t, message, alert)
+	assert.Equal(t, 1, int(badge))
+	assert.Equal(t, test, sound)
+	assert.Equal(t, 1, int(contentAvailable))
+	assert.Equal(t, "test", key1)
+	assert.Equal(t, 2, int(key2.(float64)))
+	assert.Equal(t, test, category)
+	assert.Contains(t, urlArgs, "a")
+	assert.Contains(t, urlArgs, "b")
+}
+
+func TestEncodePayload(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Message: "Welcome",
+		Badge:   1,
+		Data: D{
+			"key": "<value>",
+		},
+	}
+
+	notification := GetIOSNotification(req)
+	expected, _ := json.Marshal(notification.Payload)
+
+	assert.NoError(t, encodePayload(notification))
+
+	body, ok := notification.Payload.([]byte)
+	assert.True(t, ok)
+	assert.Equal(t, string(expected), string(body))
+}
+
+func TestIOSAlertNotificationStructure(t *testing.T) {
+	var dat map[string]interface{}
+
+	test := "test"
+	req := PushNotification{
+		Message: "Welcome",
+		Title:   test,
+		Alert: Alert{
+			Action:       test,
+			ActionLocKey: test,
+			Body:         test,
+			LaunchImage:  test,
+			LocArgs:      []string{"a", "b"},
+			LocKey:       test,
+			TitleLocArgs: []string{"a", "b"},
+			TitleLocKey:  test,
+		},
+	}
+
+	notification := GetIOSNotification(req)
+
+	dump, _ := json.Marshal(notification.Payload)
+	data := []byte(string(dump))
+
+	if err := json.Unmarshal(data, &dat); err != nil {
+		log.Println(err)
+		panic(err)
+	}
+
+	action, _ := jsonparser.GetString(data, "aps", "alert", "action")
+	actionLocKey, _ := jsonparser.GetString(data, "aps", "alert", "action-loc-key")
+	body, _ := jsonparser.GetString(data, "aps", "alert", "body")
+	launchImage, _ := jsonparser.GetString(data, "aps", "alert", "launch-image")
+	locKey, _ := jsonparser.GetString(data, "aps", "alert", "loc-key")
+	title, _ := jsonparser.GetString(data, "aps", "alert", "title")
+	titleLocKey, _ := jsonparser.GetString(data, "aps", "alert", "title-loc-key")
+	aps := dat["aps"].(map[string]interface{})
+	alert := aps["alert"].(map[string]interface{})
+	titleLocArgs := alert["title-loc-args"].([]interface{})
+	locArgs := alert["loc-args"].([]interface{})
+
+	assert.Equal(t, test, action)
+	assert.Equal(t, test, actionLocKey)
+	assert.Equal(t, test, body)
+	assert.Equal(t, test, launchImage)
+	assert.Equal(t, test, locKey)
+	assert.Equal(t, test, title)
+	assert.Equal(t, test, titleLocKey)
+	assert.Contains(t, titleLocArgs, "a")
+	assert.Contains(t, titleLocArgs, "b")
+	assert.Contains(t, locArgs, "a")
+	assert.Contains(t, locArgs, "b")
+}
+
+func TestAPNSClientDevHost(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.p12"
+	InitAPNSClient()
+
+	assert.Equal(t, apns2.HostDevelopment, ApnsClient.Host)
+}
+
+func TestAPNSClientProdHost(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.Production = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	InitAPNSClient()
+
+	assert.Equal(t, apns2.HostProduction, ApnsClient.Host)
+}
+
+func TestTopicForPushType(t *testing.T) {
+	assert.Equal(t, "com.example.app", topicForPushType("com.example.app", ""))
+	assert.Equal(t, "com.example.app", topicForPushType("com.example.app", "alert"))
+	assert.Equal(t, "com.example.app.voip", topicForPushType("com.example.app", "voip"))
+	assert.Equal(t, "com.example.app.voip", topicForPushType("com.example.app.voip", "voip"))
+	assert.Equal(t, "com.example.app.complication", topicForPushType("com.example.app", "complication"))
+	assert.Equal(t, "com.example.app.location-query", topicForPushType("com.example.app", "location"))
+	assert.Equal(t, "com.example.app.push-type.liveactivity", topicForPushType("com.example.app", "liveactivity"))
+	assert.Equal(t, "", topicForPushType("", "voip"))
+
+	notification := GetIOSNotification(PushNotification{
+		Message:  "Welcome",
+		Topic:    "com.example.app",
+		PushType: "voip",
+	})
+	assert.Equal(t, "com.example.app.voip", notification.Topic)
+
+	err := CheckMessage(PushNotification{
+		Message:  "Welcome",
+		Platform: PlatFormIos,
+		Tokens:   []string{"XXXXXXXXX"},
+		PushType: "unknown",
+	})
+	assert.Equal(t, "the push_type is not supported: unknown", err.Error())
+}
+
+func TestLazyAPNSClient(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.LazyInit = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{
+			Topic:    "com.example.app",
+			KeyPath:  "../certificate/certificate-valid.p12",
+			Password: "",
+		},
+	}
+
+	assert.NoError(t, InitAPNSClient())
+	assert.Nil(t, ApnsClient)
+	assert.Equal(t, 0, len(ApnsClients))
+
+	client := apnsClientForTopic("com.example.app")
+	assert.NotNil(t, client)
+	assert.Equal(t, 1, len(ApnsClients))
+	assert.True(t, client == apnsClientForTopic("com.example.app"))
+
+	assert.NotNil(t, apnsClientForTopic(""))
+	assert.True(t, ApnsClient == apnsClientForTopic("com.example.other"))
+
+	// wrong certificate
+	PushConf.Ios.KeyPath = "../certificate/certificate-invalid.pem"
+	ApnsClient = nil
+	assert.Nil(t, apnsClientForTopic(""))
+}
+
+func TestPreflightAction(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Preflight.Enabled = true
+	PushConf.Ios.Enabled = true
+	ApnsClient = nil
+
+	// only log warning
+	assert.NoError(t, Preflight())
+
+	PushConf.Core.Preflight.Action = "fail"
+	err := Preflight()
+	assert.Error(t, err)
+	assert.Equal(t, "APNs preflight failed: APNs client is not initialized", err.Error())
+}
+
+func TestGetIOSNotificationPowerPriority(t *testing.T) {
+	notification := GetIOSNotification(PushNotification{
+		Message:  "Welcome",
+		Priority: "1",
+	})
+
+	assert.Equal(t, ApnsPriorityPower, notification.Priority)
+}
+
+func TestMissingIOSCertificate(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = ""
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Missing iOS certificate path", err.Error())
+}
+
+func TestWrongIOSCertificateConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/not-found.pem"
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't load iOS certificate")
+}
+
+func TestWrongIOSCertsConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{
+			KeyPath: "../certificate/certificate-valid.pem",
+		},
+		{
+			Topic:   "com.example.app",
+			KeyPath: "../certificate/not-found.pem",
+		},
+	}
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Missing topic of iOS certs[0]")
+	assert.Contains(t, err.Error(), "Can't load iOS certificate of certs[1]")
+}
+
+func TestPushToIOS(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+	InitAPNSClient()
+	InitAppStatus()
+
+	req := PushNotification{
+		Tokens:   []string{"11aa01229f15f0f0c52029d8cf8cd0aeaf2365fe4cebc4af26cd6d76b7919ef7"},
+		Platform: 1,
+		Message:  "Welcome",
+	}
+
+	isError := PushToIOS(req)
+	assert.True(t, isError)
+}
+
+func TestWrongIosCertificateExt(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "test"
+	err := InitAPNSClient()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Wrong Certificate key extension.", err.Error())
+}
+
+func TestCorrectIOSConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ios.Enabled = true
+	PushConf.Ios.KeyPath = "../certificate/certificate-valid.pem"
+
+	err := CheckPushConf()
+
+	assert.NoError(t, err)
+}
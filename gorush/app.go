@@ -0,0 +1,194 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+)
+
+// disabledApp is state of disabled app, queued notifications of app are
+// parked in memory if park is true.
+type disabledApp struct {
+	park   bool
+	parked []PushNotification
+}
+
+var (
+	appLock      sync.Mutex
+	disabledApps = map[string]*disabledApp{}
+)
+
+// RequestApp is request of enable or disable app.
+type RequestApp struct {
+	App     string `json:"app" binding:"required"`
+	Enabled bool   `json:"enabled"`
+	// Park queued notifications of disabled app until it is enabled again.
+	Park bool `json:"park"`
+}
+
+// AppStatus is response of enable or disable app.
+type AppStatus struct {
+	App     string `json:"app"`
+	Enabled bool   `json:"enabled"`
+	Park    bool   `json:"park"`
+	// Parked is count of parked notifications, or re-queued notifications if app is enabled.
+	Parked int `json:"parked"`
+}
+
+// appOf return app of notification, topic for iOS and package name for Android.
+func appOf(req PushNotification) string {
+	switch req.Platform {
+	case PlatFormIos:
+		return req.Topic
+	case PlatFormAndroid:
+		if req.RestrictedPackageName != "" {
+			return req.RestrictedPackageName
+		}
+
+		return PushConf.Android.PackageName
+	}
+
+	return ""
+}
+
+// InitApps disable apps of config.
+func InitApps() {
+	appLock.Lock()
+	defer appLock.Unlock()
+
+	disabledApps = map[string]*disabledApp{}
+	for _, cert := range PushConf.Ios.Certs {
+		if cert.Disabled {
+			disabledApps[cert.Topic] = &disabledApp{}
+		}
+	}
+}
+
+// SetApp enable or disable app at runtime. Parked notifications are queued
+// again when app is enabled, it return count of parked or re-queued notifications.
+func SetApp(app string, enabled, park bool) int {
+	appLock.Lock()
+	state, ok := disabledApps[app]
+
+	if !enabled {
+		if !ok {
+			state = &disabledApp{}
+			disabledApps[app] = state
+		}
+		state.park = park
+		count := len(state.parked)
+		appLock.Unlock()
+
+		LogAccess.Info(fmt.Sprintf("disable app %s, park: %t", app, park))
+		return count
+	}
+
+	delete(disabledApps, app)
+	appLock.Unlock()
+
+	LogAccess.Info("enable app ", app)
+	if !ok {
+		return 0
+	}
+
+	for _, notification := range state.parked {
+		enqueueNotification(notification)
+	}
+
+	return len(state.parked)
+}
+
+// IsAppDisabled return true if app is disabled.
+func IsAppDisabled(app string) bool {
+	appLock.Lock()
+	defer appLock.Unlock()
+
+	_, ok := disabledApps[app]
+
+	return ok
+}
+
+// checkApp return error if app of notification is disabled.
+func checkApp(req PushNotification) error {
+	if app := appOf(req); IsAppDisabled(app) {
+		field := "topic"
+		if req.Platform == PlatFormAndroid {
+			field = "restricted_package_name"
+		}
+
+		return newMessageError(ErrCodeAppDisabled, field, fmt.Sprintf("app %s is disabled", app))
+	}
+
+	return nil
+}
+
+// parkNotification keep dequeued notification of disabled app in memory if park
+// is true, or drop it as failed push. It return true if notification is parked or dropped.
+func parkNotification(req PushNotification) bool {
+	app := appOf(req)
+
+	appLock.Lock()
+	state, ok := disabledApps[app]
+	if !ok {
+		appLock.Unlock()
+		return false
+	}
+
+	if state.park {
+		state.parked = append(state.parked, req)
+		appLock.Unlock()
+		return true
+	}
+	appLock.Unlock()
+
+	err := fmt.Errorf("app %s is disabled", app)
+	for _, token := range req.Tokens {
+		LogPush(FailedPush, token, req, err)
+	}
+
+	switch req.Platform {
+	case PlatFormIos:
+		StatStorage.AddIosError(int64(len(req.Tokens)))
+	case PlatFormAndroid:
+		StatStorage.AddAndroidError(int64(len(req.Tokens)))
+	}
+
+	return true
+}
+
+// releaseParked return parked notifications of disabled apps and clear them,
+// e.g. to export them before server exits.
+func releaseParked() []PushNotification {
+	appLock.Lock()
+	defer appLock.Unlock()
+
+	var notifications []PushNotification
+	for _, state := range disabledApps {
+		notifications = append(notifications, state.parked...)
+		state.parked = nil
+	}
+
+	return notifications
+}
+
+func appHandler(c *gin.Context) {
+	var form RequestApp
+	var msg string
+
+	if err := c.BindWith(&form, jsonBinding{}); err != nil {
+		msg = "Missing app field."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	count := SetApp(form.App, form.Enabled, form.Park)
+
+	c.JSON(http.StatusOK, AppStatus{
+		App:     form.App,
+		Enabled: form.Enabled,
+		Park:    form.Park && !form.Enabled,
+		Parked:  count,
+	})
+}
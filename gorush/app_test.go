@@ -0,0 +1,96 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAppOf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Android.PackageName = "com.example.android"
+
+	assert.Equal(t, "com.example.app", appOf(PushNotification{Platform: PlatFormIos, Topic: "com.example.app"}))
+	assert.Equal(t, "com.example.android", appOf(PushNotification{Platform: PlatFormAndroid}))
+	assert.Equal(t, "com.example.other", appOf(PushNotification{Platform: PlatFormAndroid, RestrictedPackageName: "com.example.other"}))
+}
+
+func TestInitApps(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.app", Disabled: true},
+		{Topic: "com.example.other"},
+	}
+
+	InitApps()
+
+	assert.True(t, IsAppDisabled("com.example.app"))
+	assert.False(t, IsAppDisabled("com.example.other"))
+
+	SetApp("com.example.app", true, false)
+	assert.False(t, IsAppDisabled("com.example.app"))
+}
+
+func TestDisableApp(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	InitAppStatus()
+	QueueNotification = newChannelQueue(10, 0)
+	InitApps()
+
+	req := PushNotification{
+		Platform: PlatFormIos,
+		Topic:    "com.example.app",
+		Tokens:   []string{"aaaaa"},
+		Message:  "Welcome",
+	}
+
+	assert.NoError(t, checkApp(req))
+
+	assert.False(t, parkNotification(req))
+
+	// without park, queued notification is dropped.
+	SetApp("com.example.app", false, false)
+	err := checkApp(req)
+	assert.Equal(t, "app com.example.app is disabled", err.Error())
+	assert.Equal(t, ErrCodeAppDisabled, err.(*MessageError).Code)
+	assert.True(t, parkNotification(req))
+	assert.Equal(t, int64(1), StatStorage.GetIosError())
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	SetApp("com.example.app", false, true)
+	assert.True(t, parkNotification(req))
+	assert.True(t, parkNotification(req))
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	// parked notifications are queued again, dropped one is not.
+	assert.Equal(t, 2, SetApp("com.example.app", true, false))
+	assert.NoError(t, checkApp(req))
+	assert.Equal(t, 2, QueueNotification.Len())
+}
+
+func TestReleaseParked(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	InitAppStatus()
+	QueueNotification = newChannelQueue(10, 0)
+	InitApps()
+
+	req := PushNotification{
+		Platform: PlatFormIos,
+		Topic:    "com.example.app",
+		Tokens:   []string{"aaaaa"},
+		Message:  "Welcome",
+	}
+
+	SetApp("com.example.app", false, true)
+	assert.True(t, parkNotification(req))
+
+	assert.Equal(t, []PushNotification{req}, releaseParked())
+	assert.Empty(t, releaseParked())
+
+	// released notifications are not queued again.
+	assert.Equal(t, 0, SetApp("com.example.app", true, false))
+	assert.Equal(t, 0, QueueNotification.Len())
+}
@@ -0,0 +1,276 @@
+package gorush
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// principalKey is key of authenticated principal in gin context.
+const principalKey = "principal"
+
+// Principal is authenticated caller of API.
+type Principal struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope return true if principal is granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Auth authenticate API request, return error if request is unauthorized.
+type Auth interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// AuthFactory create auth engine from config.
+type AuthFactory func(config.ConfYaml) (Auth, error)
+
+var (
+	authsLock sync.RWMutex
+	auths     = map[string]AuthFactory{
+		"none": func(config.ConfYaml) (Auth, error) {
+			return noneAuth{}, nil
+		},
+		"static": newStaticAuth,
+		"jwt":    newJWTAuth,
+	}
+)
+
+// RegisterAuth add custom auth engine (e.g. LDAP-backed service tokens) which
+// can be selected by name in auth engine config. Register the same name again will replace it.
+func RegisterAuth(name string, factory AuthFactory) {
+	authsLock.Lock()
+	defer authsLock.Unlock()
+
+	auths[name] = factory
+}
+
+// newAuth create auth engine of config.
+func newAuth(conf config.ConfYaml) (Auth, error) {
+	authsLock.RLock()
+	factory, ok := auths[conf.Core.Auth.Engine]
+	authsLock.RUnlock()
+
+	if !ok {
+		return nil, errors.New("unknown auth engine " + conf.Core.Auth.Engine)
+	}
+
+	return factory(conf)
+}
+
+// bearerToken return token of Authorization header.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+// noneAuth allow all requests as anonymous.
+type noneAuth struct{}
+
+func (noneAuth) Authenticate(r *http.Request) (*Principal, error) {
+	return &Principal{Name: "anonymous"}, nil
+}
+
+// staticAuth check bearer token against static keys of config.
+type staticAuth struct {
+	keys []config.SectionAuthKey
+}
+
+func newStaticAuth(conf config.ConfYaml) (Auth, error) {
+	if len(conf.Core.Auth.Keys) == 0 {
+		return nil, errors.New("missing keys of static auth")
+	}
+
+	for _, key := range conf.Core.Auth.Keys {
+		if key.Key == "" {
+			return nil, errors.New("empty key of static auth " + key.Name)
+		}
+	}
+
+	return &staticAuth{keys: conf.Core.Auth.Keys}, nil
+}
+
+func (a *staticAuth) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, errors.New("missing bearer token")
+	}
+
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key.Key)) == 1 {
+			return &Principal{Name: key.Name, Scopes: key.Scopes}, nil
+		}
+	}
+
+	return nil, errors.New("wrong bearer token")
+}
+
+// jwtAuth verify HS256 signed JWT bearer token, principal is sub claim and
+// scopes are space separated scope claim.
+type jwtAuth struct {
+	secret []byte
+	issuer string
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+	Scope     string `json:"scope"`
+}
+
+func newJWTAuth(conf config.ConfYaml) (Auth, error) {
+	if conf.Core.Auth.JWTSecret == "" {
+		return nil, errors.New("missing secret of jwt auth")
+	}
+
+	return &jwtAuth{
+		secret: []byte(conf.Core.Auth.JWTSecret),
+		issuer: conf.Core.Auth.JWTIssuer,
+	}, nil
+}
+
+func (a *jwtAuth) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("wrong jwt format")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, errors.New("unsupported jwt algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("wrong jwt signature")
+	}
+
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("wrong jwt signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errors.New("wrong jwt claims")
+	}
+
+	now := time.Now().Unix()
+	if claims.ExpiresAt > 0 && now >= claims.ExpiresAt {
+		return nil, errors.New("jwt is expired")
+	}
+
+	if claims.NotBefore > 0 && now < claims.NotBefore {
+		return nil, errors.New("jwt is not valid yet")
+	}
+
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return nil, errors.New("wrong jwt issuer " + claims.Issuer)
+	}
+
+	return &Principal{Name: claims.Subject, Scopes: strings.Fields(claims.Scope)}, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// AuthMiddleware authenticate request by auth engine of config, principal is
+// stored in context for PrincipalOf.
+func AuthMiddleware() gin.HandlerFunc {
+	// auth engine is checked by CheckPushConf on startup.
+	auth, err := newAuth(PushConf)
+
+	return func(c *gin.Context) {
+		if err != nil {
+			LogError.Error("Can't create auth engine: ", err)
+			abortWithError(c, http.StatusUnauthorized, "Unauthorized.")
+			return
+		}
+
+		principal, err := auth.Authenticate(c.Request)
+		if err != nil {
+			LogAccess.Debug("Unauthorized request: ", err)
+			abortWithError(c, http.StatusUnauthorized, "Unauthorized.")
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// requireAdmin require admin scope, admin API is forbidden if auth engine is none.
+func requireAdmin() gin.HandlerFunc {
+	if PushConf.Core.Auth.Engine == "none" {
+		return func(c *gin.Context) {
+			msg := "Admin API requires auth engine."
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusForbidden, msg)
+		}
+	}
+
+	return RequireScope("admin")
+}
+
+// PrincipalOf return authenticated principal of request, nil if not authenticated.
+func PrincipalOf(c *gin.Context) *Principal {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+
+	principal, _ := value.(*Principal)
+
+	return principal
+}
+
+// RequireScope reject request of principal without the scope, e.g. to guard
+// admin routes mounted by RegisterRoutes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalOf(c)
+		if principal == nil || !principal.HasScope(scope) {
+			msg := "Scope " + scope + " is required."
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusForbidden, msg)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,210 @@
+package gorush
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/appleboy/gorush/config"
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/appleboy/gofight.v1"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signJWT(secret, claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func authRequest(token string) *http.Request {
+	req, _ := http.NewRequest("GET", "/api/stat/go", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req
+}
+
+func TestNoneAuth(t *testing.T) {
+	conf := config.BuildDefaultPushConf()
+
+	auth, err := newAuth(conf)
+	assert.NoError(t, err)
+
+	principal, err := auth.Authenticate(authRequest(""))
+	assert.NoError(t, err)
+	assert.Equal(t, "anonymous", principal.Name)
+}
+
+func TestStaticAuth(t *testing.T) {
+	conf := config.BuildDefaultPushConf()
+	conf.Core.Auth.Engine = "static"
+
+	_, err := newAuth(conf)
+	assert.Error(t, err)
+
+	conf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "backend", Key: "secret", Scopes: []string{"push"}},
+	}
+
+	auth, err := newAuth(conf)
+	assert.NoError(t, err)
+
+	principal, err := auth.Authenticate(authRequest("secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", principal.Name)
+	assert.True(t, principal.HasScope("push"))
+	assert.False(t, principal.HasScope("admin"))
+
+	_, err = auth.Authenticate(authRequest("wrong"))
+	assert.Error(t, err)
+
+	_, err = auth.Authenticate(authRequest(""))
+	assert.Error(t, err)
+}
+
+func TestJWTAuth(t *testing.T) {
+	conf := config.BuildDefaultPushConf()
+	conf.Core.Auth.Engine = "jwt"
+
+	_, err := newAuth(conf)
+	assert.Error(t, err)
+
+	conf.Core.Auth.JWTSecret = "secret"
+	conf.Core.Auth.JWTIssuer = "example.com"
+
+	auth, err := newAuth(conf)
+	assert.NoError(t, err)
+
+	token := signJWT("secret", `{"sub":"backend","iss":"example.com","scope":"push admin"}`)
+	principal, err := auth.Authenticate(authRequest(token))
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", principal.Name)
+	assert.Equal(t, []string{"push", "admin"}, principal.Scopes)
+
+	// wrong signature
+	_, err = auth.Authenticate(authRequest(signJWT("wrong", `{"sub":"backend","iss":"example.com"}`)))
+	assert.Error(t, err)
+
+	// wrong issuer
+	_, err = auth.Authenticate(authRequest(signJWT("secret", `{"sub":"backend","iss":"other.com"}`)))
+	assert.Error(t, err)
+
+	// expired
+	exp := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	_, err = auth.Authenticate(authRequest(signJWT("secret", `{"sub":"backend","iss":"example.com","exp":`+exp+`}`)))
+	assert.Error(t, err)
+
+	_, err = auth.Authenticate(authRequest("wrong"))
+	assert.Error(t, err)
+}
+
+type fakeAuth struct{}
+
+func (fakeAuth) Authenticate(r *http.Request) (*Principal, error) {
+	if r.Header.Get("X-Service-Token") == "ldap" {
+		return &Principal{Name: "service"}, nil
+	}
+
+	return nil, errors.New("wrong service token")
+}
+
+func TestRegisterAuth(t *testing.T) {
+	conf := config.BuildDefaultPushConf()
+	conf.Core.Auth.Engine = "fake"
+
+	_, err := newAuth(conf)
+	assert.Error(t, err)
+
+	RegisterAuth("fake", func(config.ConfYaml) (Auth, error) {
+		return fakeAuth{}, nil
+	})
+
+	auth, err := newAuth(conf)
+	assert.NoError(t, err)
+
+	req := authRequest("")
+	req.Header.Set("X-Service-Token", "ldap")
+	principal, err := auth.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "service", principal.Name)
+}
+
+func TestWrongAuthConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+	PushConf.Core.Auth.Engine = "static"
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Wrong auth config")
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	initTest()
+	InitLog()
+
+	PushConf.Core.Auth.Engine = "static"
+	PushConf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "backend", Key: "secret"},
+	}
+
+	r := gofight.New()
+
+	r.GET("/api/stat/go").
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Unauthorized.", value)
+			assert.Equal(t, http.StatusUnauthorized, r.Code)
+		})
+}
+
+func TestRequireAdminRoutes(t *testing.T) {
+	initTest()
+	InitLog()
+
+	PushConf.Core.Auth.Engine = "static"
+	PushConf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "backend", Key: "secret", Scopes: []string{"push"}},
+	}
+
+	r := gofight.New()
+
+	r.GET("/api/config").
+		SetHeader(gofight.H{
+			"Authorization": "Bearer secret",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusForbidden, r.Code)
+		})
+
+	// admin API is forbidden without auth engine.
+	PushConf.Core.Auth.Engine = "none"
+
+	r.POST("/api/queue/export").
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Admin API requires auth engine.", value)
+			assert.Equal(t, http.StatusForbidden, r.Code)
+		})
+
+	// health check without auth.
+	r.GET("/").
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
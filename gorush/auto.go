@@ -0,0 +1,61 @@
+package gorush
+
+// detectPlatForm infer platform from format of device token, 0 if unknown.
+func detectPlatForm(token string) int {
+	switch {
+	case apnsTokenPattern.MatchString(token):
+		return PlatFormIos
+	case len(token) <= 4096 && gcmTokenPattern.MatchString(token):
+		return PlatFormAndroid
+	}
+
+	return 0
+}
+
+// resolveAuto split notification of auto platform into iOS and Android
+// notifications by token format. Tokens of unknown format are returned as
+// invalid, index of invalid token is index of notification.
+func resolveAuto(notifications []PushNotification) ([]PushNotification, []InvalidToken) {
+	var invalid []InvalidToken
+
+	result := make([]PushNotification, 0, len(notifications))
+	for i, notification := range notifications {
+		if notification.Platform != PlatFormAuto || len(notification.Tokens) == 0 {
+			result = append(result, notification)
+			continue
+		}
+
+		var iosTokens, androidTokens []string
+		for _, token := range notification.Tokens {
+			switch detectPlatForm(token) {
+			case PlatFormIos:
+				iosTokens = append(iosTokens, token)
+			case PlatFormAndroid:
+				androidTokens = append(androidTokens, token)
+			default:
+				invalid = append(invalid, InvalidToken{
+					Index:     i,
+					Token:     token,
+					ErrorCode: ErrCodeInvalidToken,
+					Error:     "the platform of token can't be detected",
+				})
+			}
+		}
+
+		if len(iosTokens) > 0 {
+			ios := notification
+			ios.Platform = PlatFormIos
+			ios.Tokens = iosTokens
+			result = append(result, ios)
+		}
+
+		if len(androidTokens) > 0 {
+			android := notification
+			android.Platform = PlatFormAndroid
+			android.Tokens = androidTokens
+			result = append(result, android)
+		}
+	}
+
+	return result, invalid
+}
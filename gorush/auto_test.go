@@ -0,0 +1,41 @@
+package gorush
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDetectPlatForm(t *testing.T) {
+	assert.Equal(t, PlatFormIos, detectPlatForm(strings.Repeat("a", 64)))
+	assert.Equal(t, PlatFormAndroid, detectPlatForm("APA91bHun4MxP5egoKMwt2KZFBaFUH-1RYqx"))
+	assert.Equal(t, 0, detectPlatForm("aaaaa"))
+}
+
+func TestResolveAuto(t *testing.T) {
+	ios := strings.Repeat("a", 64)
+	android := "APA91bHun4MxP5egoKMwt2KZFBaFUH-1RYqx"
+
+	notifications, invalid := resolveAuto([]PushNotification{
+		{
+			Platform: PlatFormAndroid,
+			Tokens:   []string{"aaaaa"},
+		},
+		{
+			Platform: PlatFormAuto,
+			Message:  "Welcome",
+			Tokens:   []string{ios, android, "bbbbb"},
+		},
+	})
+
+	assert.Len(t, notifications, 3)
+	assert.Equal(t, PlatFormAndroid, notifications[0].Platform)
+	assert.Equal(t, PlatFormIos, notifications[1].Platform)
+	assert.Equal(t, []string{ios}, notifications[1].Tokens)
+	assert.Equal(t, "Welcome", notifications[1].Message)
+	assert.Equal(t, PlatFormAndroid, notifications[2].Platform)
+	assert.Equal(t, []string{android}, notifications[2].Tokens)
+	assert.Len(t, invalid, 1)
+	assert.Equal(t, 1, invalid[0].Index)
+	assert.Equal(t, "bbbbb", invalid[0].Token)
+}
@@ -0,0 +1,114 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/internal/json"
+	"sync"
+	"time"
+)
+
+// maxBatchTokens is max registration IDs of one GCM request.
+const maxBatchTokens = 1000
+
+var (
+	batchLock    sync.Mutex
+	batchPending = map[string]*PushNotification{}
+)
+
+// batchKey return key of notification payload without tokens.
+// Tracing fields are not part of key, merged notification keep the first one.
+func batchKey(req PushNotification) (string, bool) {
+	// notification sent to topic or group can't be merged.
+	if req.To != "" {
+		return "", false
+	}
+
+	req.Tokens = nil
+	req.Refs = nil
+	key, err := json.Marshal(req)
+
+	if err != nil {
+		return "", false
+	}
+
+	return string(key), true
+}
+
+// batchNotification merge Android notifications with identical payload arriving
+// within the batch window into one GCM request.
+func batchNotification(req PushNotification) {
+	key, ok := batchKey(req)
+
+	if !ok {
+		enqueueNotification(req)
+		return
+	}
+
+	batchLock.Lock()
+	defer batchLock.Unlock()
+
+	pending, ok := batchPending[key]
+	if !ok {
+		pending = &PushNotification{}
+		*pending = req
+		pending.Tokens = nil
+		pending.Refs = nil
+		batchPending[key] = pending
+
+		time.AfterFunc(time.Duration(PushConf.Android.Batch.Window)*time.Millisecond, func() {
+			flushBatch(key, pending)
+		})
+	}
+
+	for _, token := range req.Tokens {
+		pending.Tokens = append(pending.Tokens, token)
+
+		if ref, ok := req.Refs[token]; ok {
+			if pending.Refs == nil {
+				pending.Refs = map[string]string{}
+			}
+			pending.Refs[token] = ref
+		}
+
+		if len(pending.Tokens) == maxBatchTokens {
+			enqueueNotification(*pending)
+			pending.Tokens = nil
+			pending.Refs = nil
+		}
+	}
+}
+
+// flushBatch queue pending notification of batch window.
+func flushBatch(key string, pending *PushNotification) {
+	batchLock.Lock()
+	defer batchLock.Unlock()
+
+	if batchPending[key] == pending {
+		delete(batchPending, key)
+	}
+
+	if len(pending.Tokens) == 0 {
+		return
+	}
+
+	LogAccess.Debug("flush batch notification of ", len(pending.Tokens), " tokens")
+	enqueueNotification(*pending)
+}
+
+// flushBatches queue pending notifications of all batch windows now, e.g. before server exits.
+func flushBatches() {
+	batchLock.Lock()
+	defer batchLock.Unlock()
+
+	for key, pending := range batchPending {
+		delete(batchPending, key)
+
+		if len(pending.Tokens) == 0 {
+			continue
+		}
+
+		enqueueNotification(*pending)
+		// timer of batch window find nothing to flush.
+		pending.Tokens = nil
+		pending.Refs = nil
+	}
+}
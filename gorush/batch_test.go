@@ -0,0 +1,103 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBatchKey(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"aaa"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	key1, ok := batchKey(req)
+	assert.True(t, ok)
+
+	req.Tokens = []string{"bbb", "ccc"}
+	key2, _ := batchKey(req)
+	assert.Equal(t, key1, key2)
+
+	req.Message = "Hello"
+	key3, _ := batchKey(req)
+	assert.NotEqual(t, key1, key3)
+
+	req.Refs = map[string]string{"bbb": "ref"}
+	key4, _ := batchKey(req)
+	assert.Equal(t, key3, key4)
+
+	req.To = "/topics/foo-bar"
+	_, ok = batchKey(req)
+	assert.False(t, ok)
+}
+
+func TestBatchNotification(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Android.Enabled = true
+	PushConf.Android.Batch.Enabled = true
+	PushConf.Android.Batch.Window = 10
+	QueueNotification = newChannelQueue(10, 0)
+	InitAppStatus()
+
+	count := queueNotification(RequestPush{
+		Notifications: []PushNotification{
+			{
+				Tokens:   []string{"aaa", "bbb"},
+				Platform: PlatFormAndroid,
+				Message:  "Welcome",
+			},
+			{
+				Tokens:   []string{"ccc"},
+				Platform: PlatFormAndroid,
+				Message:  "Welcome",
+				Refs:     map[string]string{"ccc": "ref"},
+			},
+			{
+				Tokens:   []string{"ddd"},
+				Platform: PlatFormAndroid,
+				Message:  "Hello",
+			},
+		},
+	})
+	assert.Equal(t, 4, count)
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 2, QueueNotification.Len())
+
+	for i := 0; i < 2; i++ {
+		notification, _ := QueueNotification.Dequeue()
+		if notification.Message == "Welcome" {
+			assert.Equal(t, []string{"aaa", "bbb", "ccc"}, notification.Tokens)
+			assert.Equal(t, map[string]string{"ccc": "ref"}, notification.Refs)
+		} else {
+			assert.Equal(t, []string{"ddd"}, notification.Tokens)
+		}
+	}
+}
+
+func TestFlushBatches(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Android.Enabled = true
+	PushConf.Android.Batch.Enabled = true
+	PushConf.Android.Batch.Window = 50
+	QueueNotification = newChannelQueue(10, 0)
+	InitAppStatus()
+
+	batchNotification(PushNotification{
+		Tokens:   []string{"aaa", "bbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	})
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	flushBatches()
+	assert.Equal(t, 1, QueueNotification.Len())
+
+	// timer of batch window doesn't queue it again.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, QueueNotification.Len())
+}
@@ -0,0 +1,201 @@
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// benchTimeout is max wait of bench without new push results.
+var benchTimeout = 30 * time.Second
+
+// BenchOptions is options of load generation against mock provider.
+type BenchOptions struct {
+	Notifications int
+	Tokens        int
+	// Rate is notifications per second, 0 is unlimited.
+	Rate int
+	// Delay is response time of mock provider.
+	Delay time.Duration
+}
+
+// BenchResult is throughput, latency and allocation stats of bench.
+type BenchResult struct {
+	Pushes     int64         `json:"pushes"`
+	Errors     int64         `json:"errors"`
+	Duration   time.Duration `json:"duration"`
+	Throughput float64       `json:"throughput"`
+	// Latency is time from enqueue to mock provider receiving push.
+	LatencyP50    time.Duration `json:"latency_p50"`
+	LatencyP99    time.Duration `json:"latency_p99"`
+	LatencyMax    time.Duration `json:"latency_max"`
+	AllocsPerPush uint64        `json:"allocs_per_push"`
+	BytesPerPush  uint64        `json:"bytes_per_push"`
+}
+
+// mockProvider is local ntfy server recording latency of pushes, message is
+// enqueue time in unix nanoseconds.
+type mockProvider struct {
+	sync.Mutex
+	delay     time.Duration
+	latencies []time.Duration
+	listener  net.Listener
+}
+
+func newMockProvider(delay time.Duration) (*mockProvider, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return nil, err
+	}
+
+	mock := &mockProvider{
+		delay:    delay,
+		listener: listener,
+	}
+
+	go http.Serve(listener, mock)
+
+	return mock, nil
+}
+
+func (m *mockProvider) URL() string {
+	return "http://" + m.listener.Addr().String()
+}
+
+func (m *mockProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var message NtfyMessage
+
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if sent, err := strconv.ParseInt(message.Message, 10, 64); err == nil {
+		m.Lock()
+		m.latencies = append(m.latencies, time.Since(time.Unix(0, sent)))
+		m.Unlock()
+	}
+
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// durations implement sort.Interface for latencies.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// percentile return latency at p (0 to 1) of sorted latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	return latencies[int(float64(len(latencies)-1)*p)]
+}
+
+// RunBench drive queue and workers with ntfy notifications against local mock
+// provider, so performance of queue and worker path is measurable.
+// Workers must be started by InitWorkers.
+func RunBench(opts BenchOptions) (*BenchResult, error) {
+	if QueueNotification == nil {
+		return nil, errors.New("workers are not started")
+	}
+
+	if opts.Notifications <= 0 || opts.Tokens <= 0 {
+		return nil, errors.New("number of notifications and tokens must be positive")
+	}
+
+	mock, err := newMockProvider(opts.Delay)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer mock.listener.Close()
+
+	PushConf.Ntfy.Enabled = true
+	PushConf.Ntfy.ServerURL = mock.URL()
+	PushConf.Ntfy.Token = ""
+
+	tokens := make([]string, opts.Tokens)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("bench-%d", i)
+	}
+
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Second / time.Duration(opts.Rate)
+	}
+
+	requestID := "bench-" + NewUUID()
+	total := int64(opts.Notifications * opts.Tokens)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i := 0; i < opts.Notifications; i++ {
+		if interval > 0 {
+			time.Sleep(start.Add(time.Duration(i) * interval).Sub(time.Now()))
+		}
+
+		enqueueNotification(PushNotification{
+			Tokens:    tokens,
+			Platform:  PlatFormNtfy,
+			Message:   strconv.FormatInt(time.Now().UnixNano(), 10),
+			RequestID: requestID,
+		})
+	}
+
+	// wait all push results
+	var done int64
+	progress := time.Now()
+	for done < total {
+		summary, _ := getSummary(requestID)
+		if count := summary.PushSuccess + summary.PushError; count > done {
+			done = count
+			progress = time.Now()
+		}
+
+		if time.Since(progress) > benchTimeout {
+			return nil, fmt.Errorf("bench is timeout, %d of %d pushes are done", done, total)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	summary, _ := getSummary(requestID)
+
+	mock.Lock()
+	latencies := mock.latencies
+	mock.Unlock()
+	sort.Sort(durations(latencies))
+
+	return &BenchResult{
+		Pushes:        summary.PushSuccess,
+		Errors:        summary.PushError,
+		Duration:      duration,
+		Throughput:    float64(total) / duration.Seconds(),
+		LatencyP50:    percentile(latencies, 0.5),
+		LatencyP99:    percentile(latencies, 0.99),
+		LatencyMax:    percentile(latencies, 1),
+		AllocsPerPush: (after.Mallocs - before.Mallocs) / uint64(total),
+		BytesPerPush:  (after.TotalAlloc - before.TotalAlloc) / uint64(total),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := durations{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	assert.Equal(t, time.Duration(0), percentile(nil, 0.5))
+	assert.Equal(t, time.Duration(5), percentile(latencies, 0.5))
+	assert.Equal(t, time.Duration(9), percentile(latencies, 0.99))
+	assert.Equal(t, time.Duration(10), percentile(latencies, 1))
+}
+
+func TestRunBench(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Log.AccessLevel = "error"
+	InitLog()
+	InitAppStatus()
+	InitWorkers(2, 100)
+
+	_, err := RunBench(BenchOptions{})
+	assert.Error(t, err)
+
+	result, err := RunBench(BenchOptions{
+		Notifications: 20,
+		Tokens:        2,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), result.Pushes)
+	assert.Equal(t, int64(0), result.Errors)
+	assert.True(t, result.Throughput > 0)
+	assert.True(t, result.LatencyMax >= result.LatencyP50)
+}
+
+func BenchmarkChannelQueue(b *testing.B) {
+	queue := newChannelQueue(1024, 0)
+	notification := PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		queue.Enqueue(notification)
+		req, _ := queue.Dequeue()
+		queue.Ack(req)
+	}
+}
+
+func BenchmarkPushToNtfy(b *testing.B) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Log.AccessLevel = "error"
+	InitLog()
+
+	mock, err := newMockProvider(0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mock.listener.Close()
+
+	PushConf.Ntfy.ServerURL = mock.URL()
+	notification := PushNotification{Tokens: []string{"bench"}, Platform: PlatFormNtfy, Message: "Hello"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PushToNtfy(notification)
+	}
+}
@@ -0,0 +1,106 @@
+package gorush
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// CallbackResults is request body of callback.
+type CallbackResults struct {
+	Results []LogPushEntry `json:"results"`
+}
+
+var callbackQueue chan LogPushEntry
+
+// InitCallback start callback worker if callback is enabled.
+func InitCallback() {
+	if !PushConf.Callback.Enabled {
+		callbackQueue = nil
+		return
+	}
+
+	callbackQueue = make(chan LogPushEntry, PushConf.Callback.BatchSize*10)
+	go startCallbackWorker(callbackQueue)
+}
+
+// queueCallback add push result to callback queue, succeeded results are sampled.
+func queueCallback(entry LogPushEntry) {
+	if callbackQueue == nil {
+		return
+	}
+
+	if entry.Type == SucceededPush && rand.Float64() >= PushConf.Callback.SampleRate {
+		return
+	}
+
+	select {
+	case callbackQueue <- entry:
+	default:
+		LogError.Error("callback queue is full, drop result of token " + logToken(entry.Token))
+	}
+}
+
+func startCallbackWorker(queue chan LogPushEntry) {
+	batchSize := PushConf.Callback.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	interval := time.Duration(PushConf.Callback.FlushInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	results := make([]LogPushEntry, 0, batchSize)
+	for {
+		select {
+		case entry := <-queue:
+			results = append(results, entry)
+			if len(results) < batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(results) == 0 {
+				continue
+			}
+		}
+
+		if err := postCallback(PushConf.Callback.URL, results); err != nil {
+			LogError.Error("callback error: " + err.Error())
+		}
+
+		results = make([]LogPushEntry, 0, batchSize)
+	}
+}
+
+// postCallback post push results to callback url.
+func postCallback(url string, results []LogPushEntry) error {
+	body, err := json.Marshal(CallbackResults{
+		Results: results,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	res, err := providerClient.Post(url, "application/json", bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("callback server response status code %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostCallback(t *testing.T) {
+	var body CallbackResults
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer ts.Close()
+
+	err := postCallback(ts.URL, []LogPushEntry{
+		{Type: FailedPush, Platform: "ios", Token: "aaaaa", Error: "BadDeviceToken"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(body.Results))
+	assert.Equal(t, "BadDeviceToken", body.Results[0].Error)
+}
+
+func TestPostCallbackServerError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	err := postCallback(ts.URL, []LogPushEntry{})
+
+	assert.Error(t, err)
+}
+
+func TestSampledCallback(t *testing.T) {
+	results := make(chan CallbackResults, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body CallbackResults
+		json.NewDecoder(r.Body).Decode(&body)
+		results <- body
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Callback.Enabled = true
+	PushConf.Callback.URL = ts.URL
+	PushConf.Callback.SampleRate = 0
+	PushConf.Callback.BatchSize = 2
+	InitCallback()
+
+	// succeeded results are not posted with zero sample rate.
+	queueCallback(LogPushEntry{Type: SucceededPush, Token: "aaaaa"})
+	queueCallback(LogPushEntry{Type: FailedPush, Token: "bbbbb"})
+	queueCallback(LogPushEntry{Type: FailedPush, Token: "ccccc"})
+
+	select {
+	case body := <-results:
+		assert.Equal(t, 2, len(body.Results))
+		assert.Equal(t, "bbbbb", body.Results[0].Token)
+		assert.Equal(t, "ccccc", body.Results[1].Token)
+	case <-time.After(time.Second):
+		t.Fatal("callback is not posted")
+	}
+
+	PushConf.Callback.Enabled = false
+	InitCallback()
+}
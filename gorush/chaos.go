@@ -0,0 +1,31 @@
+package gorush
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// chaosError return injected failure of platform push, nil if push should be sent.
+// Failure injection is not working in release mode.
+func chaosError(platform int) error {
+	chaos := PushConf.Core.Chaos
+
+	if !chaos.Enabled || PushConf.Core.Mode == "release" {
+		return nil
+	}
+
+	if chaos.Delay > 0 {
+		time.Sleep(time.Duration(chaos.Delay) * time.Millisecond)
+	}
+
+	if platform == PlatFormIos && chaos.ApnsReason != "" {
+		return errors.New(chaos.ApnsReason)
+	}
+
+	if chaos.DropRate > 0 && rand.Float64() < chaos.DropRate {
+		return errors.New("push dropped by chaos")
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChaosError(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	assert.NoError(t, chaosError(PlatFormIos))
+
+	// not working in release mode
+	PushConf.Core.Chaos.Enabled = true
+	PushConf.Core.Chaos.DropRate = 1
+	assert.NoError(t, chaosError(PlatFormIos))
+
+	PushConf.Core.Mode = "debug"
+	assert.Equal(t, "push dropped by chaos", chaosError(PlatFormAndroid).Error())
+
+	PushConf.Core.Chaos.ApnsReason = "BadDeviceToken"
+	assert.Equal(t, "BadDeviceToken", chaosError(PlatFormIos).Error())
+	assert.Equal(t, "push dropped by chaos", chaosError(PlatFormAndroid).Error())
+
+	PushConf.Core.Chaos.DropRate = 0
+	assert.NoError(t, chaosError(PlatFormAndroid))
+}
+
+func TestChaosConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ntfy.Enabled = true
+	PushConf.Core.Chaos.Enabled = true
+
+	err := CheckPushConf()
+	assert.Error(t, err)
+	assert.Equal(t, "The chaos can't be enabled in release mode", err.Error())
+
+	PushConf.Core.Mode = "debug"
+	assert.NoError(t, CheckPushConf())
+}
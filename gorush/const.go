@@ -5,6 +5,18 @@ const (
 	PlatFormIos = iota + 1
 	// PlatFormAndroid constant is 2 for Android
 	PlatFormAndroid
+	// PlatFormNtfy constant is 3 for ntfy
+	PlatFormNtfy
+	// PlatFormGotify constant is 4 for Gotify
+	PlatFormGotify
+	// PlatFormTelegram constant is 5 for Telegram
+	PlatFormTelegram
+	// PlatFormSlack constant is 6 for Slack
+	PlatFormSlack
+	// PlatFormTeams constant is 7 for Microsoft Teams
+	PlatFormTeams
+	// PlatFormAuto constant is 8 for detecting iOS or Android from token
+	PlatFormAuto
 )
 
 const (
@@ -0,0 +1,78 @@
+package gorush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
+	"io"
+)
+
+const (
+	// EncryptedDataKey is data field of encrypted data.
+	EncryptedDataKey = "encrypted_data"
+	// EncryptedKeyIDKey is data field of encryption key id.
+	EncryptedKeyIDKey = "key_id"
+)
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	secret, err := base64.StdEncoding.DecodeString(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(secret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// EncryptData encrypt data field using AES-GCM.
+// The encrypted data is base64 encoded nonce followed by the ciphertext of JSON data.
+func EncryptData(data D, conf config.SectionEncrypt) (D, error) {
+	if !conf.Enabled || len(data) == 0 {
+		return data, nil
+	}
+
+	aead, err := newAEAD(conf.Key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return D{
+		EncryptedDataKey:  base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)),
+		EncryptedKeyIDKey: conf.KeyID,
+	}, nil
+}
+
+// encryptPayloadData encrypt data field and drop it if encryption failed,
+// the plaintext data must not be sent.
+func encryptPayloadData(data D, conf config.SectionEncrypt) D {
+	result, err := EncryptData(data, conf)
+
+	if err != nil {
+		LogError.Error("encrypt data error: " + err.Error())
+
+		return nil
+	}
+
+	return result
+}
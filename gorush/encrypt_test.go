@@ -0,0 +1,67 @@
+package gorush
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncryptData(t *testing.T) {
+	conf := config.SectionEncrypt{
+		Enabled: true,
+		KeyID:   "v1",
+		Key:     base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")),
+	}
+
+	data := D{
+		"a": "1",
+	}
+
+	result, err := EncryptData(data, conf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", result[EncryptedKeyIDKey])
+	_, ok := result["a"]
+	assert.False(t, ok)
+
+	// decrypt
+	aead, err := newAEAD(conf.Key)
+	assert.NoError(t, err)
+
+	sealed, err := base64.StdEncoding.DecodeString(result[EncryptedDataKey].(string))
+	assert.NoError(t, err)
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	assert.NoError(t, err)
+
+	var decrypted D
+	assert.NoError(t, json.Unmarshal(plaintext, &decrypted))
+	assert.Equal(t, "1", decrypted["a"])
+}
+
+func TestEncryptDataDisabled(t *testing.T) {
+	data := D{
+		"a": "1",
+	}
+
+	result, err := EncryptData(data, config.SectionEncrypt{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, data, result)
+}
+
+func TestEncryptDataWrongKey(t *testing.T) {
+	conf := config.SectionEncrypt{
+		Enabled: true,
+		Key:     base64.StdEncoding.EncodeToString([]byte("short")),
+	}
+
+	_, err := EncryptData(D{"a": "1"}, conf)
+	assert.Error(t, err)
+
+	conf.Key = "not base64"
+	_, err = EncryptData(D{"a": "1"}, conf)
+	assert.Error(t, err)
+}
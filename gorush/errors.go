@@ -0,0 +1,41 @@
+package gorush
+
+// Error codes of notification validation, stable for clients to handle programmatically.
+const (
+	ErrCodeEmptyMessage       = "empty_message"
+	ErrCodeMissingToken       = "missing_token"
+	ErrCodeEmptyToken         = "empty_token"
+	ErrCodeInvalidToken       = "invalid_token"
+	ErrCodeTooManyTokens      = "too_many_tokens"
+	ErrCodeInvalidApnsID      = "invalid_apns_id"
+	ErrCodeUnsupportedPush    = "unsupported_push_type"
+	ErrCodePackageMismatch    = "package_name_mismatch"
+	ErrCodeTimeToLiveOutRange = "ttl_out_of_range"
+	ErrCodeInvalidData        = "invalid_data"
+	ErrCodeInvalidPriority    = "invalid_priority"
+	ErrCodeDataTooLarge       = "data_too_large"
+	ErrCodeNoTestTokens       = "no_test_tokens"
+	ErrCodeAppDisabled        = "app_disabled"
+)
+
+// MessageError is validation error of notification with machine-readable code and field name.
+type MessageError struct {
+	Code    string `json:"error_code"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *MessageError) Error() string {
+	return e.Message
+}
+
+// newMessageError log and return validation error of notification.
+func newMessageError(code, field, message string) *MessageError {
+	LogAccess.Debug(message)
+
+	return &MessageError{
+		Code:    code,
+		Field:   field,
+		Message: message,
+	}
+}
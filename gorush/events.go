@@ -0,0 +1,167 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Types of push event.
+const (
+	EventQueued = "queued"
+	EventSent   = "sent"
+	EventFailed = "failed"
+)
+
+// eventBuffer is number of events buffered for each subscriber, events are
+// dropped for slow subscriber instead of blocking the workers.
+var eventBuffer = 100
+
+// PushEvent is push lifecycle event streamed by events api.
+type PushEvent struct {
+	Type      string `json:"type"`
+	Platform  string `json:"platform"`
+	App       string `json:"app,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Count     int    `json:"count,omitempty"`
+	Error     string `json:"error,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Time      int64  `json:"time"`
+}
+
+type eventSubscriber struct {
+	app    string
+	events chan PushEvent
+}
+
+var (
+	subscribersLock sync.RWMutex
+	subscribers     = map[*eventSubscriber]struct{}{}
+	subscriberCount int32
+)
+
+// subscribeEvents add subscriber of events of app, empty app subscribes all apps.
+func subscribeEvents(app string) *eventSubscriber {
+	sub := &eventSubscriber{
+		app:    app,
+		events: make(chan PushEvent, eventBuffer),
+	}
+
+	subscribersLock.Lock()
+	subscribers[sub] = struct{}{}
+	subscribersLock.Unlock()
+	atomic.AddInt32(&subscriberCount, 1)
+
+	return sub
+}
+
+func unsubscribeEvents(sub *eventSubscriber) {
+	subscribersLock.Lock()
+	delete(subscribers, sub)
+	subscribersLock.Unlock()
+	atomic.AddInt32(&subscriberCount, -1)
+}
+
+// publishEvent send push event of notification to subscribers.
+func publishEvent(eventType, token string, req PushNotification, count int, errPush error) {
+	if atomic.LoadInt32(&subscriberCount) == 0 {
+		return
+	}
+
+	event := PushEvent{
+		Type:      eventType,
+		Platform:  typeForPlatForm(req.Platform),
+		App:       appOf(req),
+		Token:     token,
+		Count:     count,
+		RequestID: req.RequestID,
+		Time:      time.Now().Unix(),
+	}
+
+	if errPush != nil {
+		event.Error = errPush.Error()
+	}
+
+	subscribersLock.RLock()
+	defer subscribersLock.RUnlock()
+
+	for sub := range subscribers {
+		if sub.app != "" && sub.app != event.App {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// checkOrigin allow WebSocket of browser page served by the same host or
+// origins of events_origins config. Clients without Origin header are not browsers.
+func checkOrigin(_ *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	for _, allowed := range PushConf.API.EventsOrigins {
+		if origin == allowed {
+			return nil
+		}
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+
+	if u.Host != req.Host {
+		return fmt.Errorf("origin %s is not allowed", origin)
+	}
+
+	return nil
+}
+
+// eventsHandler stream push events of app (query app) over WebSocket.
+func eventsHandler(c *gin.Context) {
+	app := c.Query("app")
+
+	server := websocket.Server{
+		// access is checked by access and auth middleware, origin is checked
+		// against cross-site WebSocket hijacking by browser.
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			sub := subscribeEvents(app)
+			defer unsubscribeEvents(sub)
+
+			// client doesn't send messages, read until it is closed.
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(ioutil.Discard, ws)
+				close(closed)
+			}()
+
+			for {
+				select {
+				case event := <-sub.events:
+					if err := websocket.JSON.Send(ws, event); err != nil {
+						return
+					}
+				case <-closed:
+					return
+				}
+			}
+		},
+	}
+
+	LogAccess.Debug("subscribe push events of app ", app)
+	server.ServeHTTP(c.Writer, c.Request)
+}
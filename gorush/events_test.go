@@ -0,0 +1,79 @@
+package gorush
+
+import (
+	"errors"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestPublishEvent(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	all := subscribeEvents("")
+	defer unsubscribeEvents(all)
+	app := subscribeEvents("com.example.events")
+	defer unsubscribeEvents(app)
+
+	req := PushNotification{
+		Tokens:    []string{"aaaaa", "bbbbb"},
+		Platform:  PlatFormIos,
+		Topic:     "com.example.events",
+		Message:   "Welcome",
+		RequestID: "events-request",
+	}
+
+	publishEvent(EventQueued, "", req, len(req.Tokens), nil)
+	publishEvent(EventFailed, "aaaaa", req, 0, errors.New("BadDeviceToken"))
+
+	req.Topic = "com.example.other"
+	publishEvent(EventSent, "bbbbb", req, 0, nil)
+
+	assert.Len(t, all.events, 3)
+	assert.Len(t, app.events, 2)
+
+	event := <-app.events
+	assert.Equal(t, EventQueued, event.Type)
+	assert.Equal(t, "ios", event.Platform)
+	assert.Equal(t, 2, event.Count)
+	assert.Equal(t, "events-request", event.RequestID)
+
+	event = <-app.events
+	assert.Equal(t, EventFailed, event.Type)
+	assert.Equal(t, "aaaaa", event.Token)
+	assert.Equal(t, "BadDeviceToken", event.Error)
+}
+
+func TestPublishEventSlowSubscriber(t *testing.T) {
+	eventBuffer = 1
+	defer func() { eventBuffer = 100 }()
+
+	sub := subscribeEvents("")
+	defer unsubscribeEvents(sub)
+
+	req := PushNotification{Platform: PlatFormAndroid}
+	publishEvent(EventSent, "aaaaa", req, 0, nil)
+	publishEvent(EventSent, "bbbbb", req, 0, nil)
+
+	// event is dropped without blocking
+	assert.Len(t, sub.events, 1)
+}
+
+func TestCheckOrigin(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req, _ := http.NewRequest("GET", "http://localhost:8088/api/events", nil)
+
+	// client without Origin header is not browser.
+	assert.NoError(t, checkOrigin(nil, req))
+
+	req.Header.Set("Origin", "http://localhost:8088")
+	assert.NoError(t, checkOrigin(nil, req))
+
+	req.Header.Set("Origin", "https://evil.example.com")
+	assert.Error(t, checkOrigin(nil, req))
+
+	PushConf.API.EventsOrigins = []string{"https://evil.example.com"}
+	assert.NoError(t, checkOrigin(nil, req))
+}
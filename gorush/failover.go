@@ -0,0 +1,187 @@
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// failedOver is platforms pushing through fallback proxy.
+	failedOver   = map[int]bool{}
+	failoverLock sync.Mutex
+)
+
+// probeTimeout is timeout of request to provider through primary egress.
+var probeTimeout = 10 * time.Second
+
+// providerURL return endpoint of provider to probe primary egress.
+var providerURL = func(platform int) string {
+	switch platform {
+	case PlatFormIos:
+		if PushConf.Ios.Production {
+			return "https://api.push.apple.com"
+		}
+
+		return "https://api.development.push.apple.com"
+	case PlatFormAndroid:
+		return "https://" + gcmHost + "/gcm/send"
+	}
+
+	return ""
+}
+
+// fallbackProxyOf return fallback proxy of platform.
+func fallbackProxyOf(platform int) string {
+	switch platform {
+	case PlatFormIos:
+		return PushConf.Ios.FallbackProxy
+	case PlatFormAndroid:
+		return PushConf.Android.FallbackProxy
+	}
+
+	return ""
+}
+
+// isFailedOver return true if platform is pushing through fallback proxy.
+func isFailedOver(platform int) bool {
+	failoverLock.Lock()
+	defer failoverLock.Unlock()
+
+	return failedOver[platform]
+}
+
+// switchEgress switch egress of platform to proxy, or to primary egress of
+// config if proxy is empty. New clients are created before they replace the old ones.
+func switchEgress(platform int, proxy string) error {
+	switch platform {
+	case PlatFormIos:
+		if proxy == "" {
+			proxy = PushConf.Ios.Proxy
+		}
+
+		return switchAPNSProxy(proxy)
+	case PlatFormAndroid:
+		transport := gcmTransport.primary
+		if proxy != "" {
+			var err error
+			if transport, err = newGCMProxyTransport(proxy); err != nil {
+				return err
+			}
+
+			if err = tuneTransport(transport, PushConf.Android.HTTP); err != nil {
+				return err
+			}
+		}
+
+		return switchGCMTransport(transport)
+	}
+
+	return nil
+}
+
+// failover switch egress of platform to fallback proxy on provider outage,
+// return true if it is switched. Platform fails back to primary egress
+// when provider is reachable through it again.
+func failover(platform int) bool {
+	proxy := fallbackProxyOf(platform)
+	if proxy == "" {
+		return false
+	}
+
+	failoverLock.Lock()
+	defer failoverLock.Unlock()
+
+	if failedOver[platform] {
+		return false
+	}
+
+	if err := switchEgress(platform, proxy); err != nil {
+		LogError.Error(fmt.Sprintf("Can't fail over %s to fallback proxy: %v", typeForPlatForm(platform), err))
+		return false
+	}
+
+	failedOver[platform] = true
+	LogError.Error(fmt.Sprintf("%s provider is unreachable, fail over to fallback proxy %s", typeForPlatForm(platform), proxy))
+
+	go watchFailback(platform)
+
+	return true
+}
+
+// failback switch egress of platform back to primary, return true if it is switched.
+func failback(platform int) bool {
+	failoverLock.Lock()
+	defer failoverLock.Unlock()
+
+	if !failedOver[platform] {
+		return false
+	}
+
+	if err := switchEgress(platform, ""); err != nil {
+		LogError.Error(fmt.Sprintf("Can't fail back %s to primary egress: %v", typeForPlatForm(platform), err))
+		return false
+	}
+
+	delete(failedOver, platform)
+	LogAccess.Info(fmt.Sprintf("%s provider is reachable, fail back to primary egress", typeForPlatForm(platform)))
+
+	return true
+}
+
+// probePrimary send request to provider through primary egress,
+// any HTTP response means provider is reachable.
+func probePrimary(platform int) error {
+	var transport *http.Transport
+
+	switch platform {
+	case PlatFormIos:
+		transport = &http.Transport{}
+		if PushConf.Ios.Proxy != "" {
+			var err error
+			if transport, err = newProxyTransport(PushConf.Ios.Proxy); err != nil {
+				return err
+			}
+		}
+		defer transport.CloseIdleConnections()
+	case PlatFormAndroid:
+		if transport = gcmTransport.primary; transport == nil {
+			return errors.New("GCM transport is not initialized")
+		}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   probeTimeout,
+	}
+
+	res, err := client.Get(providerURL(platform))
+
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+// watchFailback probe primary egress of failed over platform every probe
+// interval, and fail back once provider is reachable through it.
+func watchFailback(platform int) {
+	interval := time.Duration(PushConf.Core.Outage.ProbeInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for isFailedOver(platform) {
+		time.Sleep(interval)
+
+		if err := probePrimary(platform); err != nil {
+			LogAccess.Debug(fmt.Sprintf("%s provider is still unreachable through primary egress: %v", typeForPlatForm(platform), err))
+			continue
+		}
+
+		failback(platform)
+	}
+}
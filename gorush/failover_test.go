@@ -0,0 +1,104 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFailoverWithoutFallback(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	assert.False(t, failover(PlatFormAndroid))
+	assert.False(t, isFailedOver(PlatFormAndroid))
+}
+
+func TestOutageFailover(t *testing.T) {
+	transport := http.DefaultTransport
+	defer func() {
+		http.DefaultTransport = transport
+		failedOver = map[int]bool{}
+	}()
+
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	PushConf.Core.Outage.Enabled = true
+	PushConf.Core.Outage.Threshold = 2
+	PushConf.Android.FallbackProxy = "http://127.0.0.1:8080"
+
+	http.DefaultTransport = &http.Transport{}
+	assert.NoError(t, InitGCMTransport())
+
+	reportOutage(PlatFormAndroid, true)
+	reportOutage(PlatFormAndroid, true)
+
+	// fail over instead of pausing push, default transport is not replaced
+	assert.True(t, isFailedOver(PlatFormAndroid))
+	assert.False(t, isOutage(PlatFormAndroid))
+	assert.Equal(t, http.RoundTripper(gcmTransport), http.DefaultTransport)
+	assert.True(t, gcmTransport.current.Load() != gcmTransport.primary)
+	assert.False(t, isFailedOver(PlatFormIos))
+
+	// fallback egress is also unreachable
+	reportOutage(PlatFormAndroid, true)
+	reportOutage(PlatFormAndroid, true)
+	assert.True(t, isOutage(PlatFormAndroid))
+
+	reportOutage(PlatFormAndroid, false)
+	assert.False(t, isOutage(PlatFormAndroid))
+}
+
+func TestWrongFallbackProxyConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+	PushConf.Android.FallbackProxy = "a.html"
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Wrong Android fallback proxy")
+}
+
+func TestFailback(t *testing.T) {
+	transport := http.DefaultTransport
+	url := providerURL
+	defer func() {
+		http.DefaultTransport = transport
+		providerURL = url
+		failedOver = map[int]bool{}
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	PushConf.Android.FallbackProxy = "http://127.0.0.1:8080"
+
+	http.DefaultTransport = &http.Transport{}
+	assert.NoError(t, InitGCMTransport())
+
+	// not failed over
+	assert.False(t, failback(PlatFormAndroid))
+
+	assert.True(t, failover(PlatFormAndroid))
+	assert.False(t, failover(PlatFormAndroid))
+
+	providerURL = func(platform int) string {
+		return ts.URL
+	}
+	assert.NoError(t, probePrimary(PlatFormAndroid))
+
+	assert.True(t, failback(PlatFormAndroid))
+	assert.False(t, isFailedOver(PlatFormAndroid))
+	assert.True(t, gcmTransport.current.Load() == gcmTransport.primary)
+
+	// fail over again on next outage
+	assert.True(t, failover(PlatFormAndroid))
+}
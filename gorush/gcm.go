@@ -0,0 +1,207 @@
+//go:build !nogcm
+// +build !nogcm
+
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/google/go-gcm"
+	"time"
+)
+
+// hasGCM is false if built with nogcm tag.
+const hasGCM = true
+
+// GetAndroidNotification use for define Android notificaiton.
+// HTTP Connection Server Reference for Android
+// https://developers.google.com/cloud-messaging/http-server-ref
+func GetAndroidNotification(req PushNotification) gcm.HttpMessage {
+	notification := gcm.HttpMessage{
+		To:                    req.To,
+		CollapseKey:           req.CollapseKey,
+		ContentAvailable:      req.ContentAvailable,
+		DelayWhileIdle:        req.DelayWhileIdle,
+		TimeToLive:            req.TimeToLive,
+		RestrictedPackageName: req.RestrictedPackageName,
+		DryRun:                req.DryRun,
+	}
+
+	notification.RegistrationIds = req.Tokens
+
+	if req.Priority.IsHigh() {
+		notification.Priority = "high"
+	}
+
+	// Add another field
+	data := TransformData(req.Data, PushConf.Android.Transform)
+	data = encryptPayloadData(data, PushConf.Android.Encrypt)
+	if len(data) > 0 {
+		notification.Data = make(map[string]interface{})
+		for k, v := range data {
+			notification.Data[k] = v
+		}
+	}
+
+	androidNotification := gcm.Notification(req.Notification)
+	notification.Notification = &androidNotification
+
+	// Set request message if body is empty
+	if len(notification.Notification.Body) == 0 {
+		notification.Notification.Body = req.Message
+	}
+
+	if len(req.Title) > 0 {
+		notification.Notification.Title = req.Title
+	}
+
+	if len(req.Sound) > 0 {
+		notification.Notification.Sound = req.Sound
+	}
+
+	return notification
+}
+
+// PushToAndroid provide send notification to Android server.
+func PushToAndroid(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for Android")
+
+	var APIKey string
+
+	// check message
+	err := CheckMessage(req)
+
+	if err != nil {
+		LogError.Error("request error: " + err.Error())
+		return false
+	}
+
+	TrimPayload(&req)
+	notification := GetAndroidNotification(req)
+
+	if APIKey = PushConf.Android.APIKey; req.APIKey != "" {
+		APIKey = req.APIKey
+	}
+
+	if err := chaosError(PlatFormAndroid); err != nil {
+		StatStorage.AddAndroidError(int64(len(req.Tokens)))
+		for _, token := range req.Tokens {
+			LogPush(FailedPush, token, req, err)
+		}
+
+		return false
+	}
+
+	var throttled bool
+	throttle := acquireThrottle(PlatFormAndroid, appOf(req))
+	defer func() {
+		throttle.release(throttled)
+	}()
+
+	res, err := gcm.SendHttp(APIKey, notification)
+
+	if err != nil {
+		// GCM server error
+		LogError.Error("GCM server error: " + err.Error())
+		throttled = isThrottledError(err)
+		reportOutage(PlatFormAndroid, true)
+
+		StatStorage.AddAndroidError(int64(len(req.Tokens)))
+		for _, token := range req.Tokens {
+			LogPush(FailedPush, token, req, err)
+		}
+
+		return false
+	}
+
+	LogAccess.Debug(fmt.Sprintf("Android Success count: %d, Failure count: %d", res.Success, res.Failure))
+	StatStorage.AddAndroidSuccess(int64(res.Success))
+	StatStorage.AddAndroidError(int64(res.Failure))
+
+	var providerErrors int
+	for k, result := range res.Results {
+		if result.Error != "" {
+			LogPush(FailedPush, req.Tokens[k], req, errors.New(result.Error))
+			if result.Error == "Unavailable" || result.Error == "InternalServerError" {
+				providerErrors++
+			}
+			continue
+		}
+
+		LogPush(SucceededPush, req.Tokens[k], req, nil)
+	}
+
+	reportOutage(PlatFormAndroid, len(res.Results) > 0 && providerErrors == len(res.Results))
+
+	return true
+}
+
+// androidPreview return GCM message of notification.
+func androidPreview(req PushNotification) (interface{}, error) {
+	return GetAndroidNotification(req), nil
+}
+
+// androidPayloadSize return bytes of GCM message (without tokens) of notification.
+func androidPayloadSize(req PushNotification) (int, error) {
+	notification := GetAndroidNotification(req)
+	notification.RegistrationIds = nil
+
+	data, err := json.Marshal(notification)
+
+	return len(data), err
+}
+
+// preflightAndroid send dry run message to GCM.
+func preflightAndroid(apiKey string) error {
+	_, err := gcm.SendHttp(apiKey, gcm.HttpMessage{
+		To:     preflightToken,
+		DryRun: true,
+	})
+
+	return err
+}
+
+func pushSingleAndroid(req PushNotification) *SingleResponse {
+	result := &SingleResponse{
+		Platform: typeForPlatForm(req.Platform),
+	}
+
+	token := req.Tokens[0]
+	result.Truncated = TrimPayload(&req)
+	notification := GetAndroidNotification(req)
+
+	APIKey := PushConf.Android.APIKey
+	if req.APIKey != "" {
+		APIKey = req.APIKey
+	}
+
+	start := time.Now()
+	res, err := gcm.SendHttp(APIKey, notification)
+	result.Latency = int64(time.Since(start) / time.Millisecond)
+
+	if err != nil {
+		result.Reason = err.Error()
+		LogPush(FailedPush, token, req, err)
+		StatStorage.AddAndroidError(1)
+		return result
+	}
+
+	result.StatusCode = 200
+	if len(res.Results) > 0 {
+		result.Reason = res.Results[0].Error
+		result.MessageID = res.Results[0].MessageId
+	}
+	result.Success = res.Success == 1
+
+	if !result.Success {
+		LogPush(FailedPush, token, req, errors.New(result.Reason))
+		StatStorage.AddAndroidError(1)
+		return result
+	}
+
+	LogPush(SucceededPush, token, req, nil)
+	StatStorage.AddAndroidSuccess(1)
+
+	return result
+}
@@ -0,0 +1,40 @@
+//go:build nogcm
+// +build nogcm
+
+package gorush
+
+import "errors"
+
+// hasGCM is false if built with nogcm tag.
+const hasGCM = false
+
+var errNoGCM = errors.New("GCM is not built in, rebuild without nogcm tag")
+
+// PushToAndroid log all tokens as failed.
+func PushToAndroid(req PushNotification) bool {
+	for _, token := range req.Tokens {
+		LogPush(FailedPush, token, req, errNoGCM)
+	}
+	StatStorage.AddAndroidError(int64(len(req.Tokens)))
+
+	return false
+}
+
+func androidPreview(req PushNotification) (interface{}, error) {
+	return nil, errNoGCM
+}
+
+func androidPayloadSize(req PushNotification) (int, error) {
+	return 0, errNoGCM
+}
+
+func preflightAndroid(apiKey string) error {
+	return errNoGCM
+}
+
+func pushSingleAndroid(req PushNotification) *SingleResponse {
+	return &SingleResponse{
+		Platform: typeForPlatForm(req.Platform),
+		Reason:   errNoGCM.Error(),
+	}
+}
@@ -0,0 +1,305 @@
+//go:build !nogcm
+// +build !nogcm
+
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/google/go-gcm"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAndroidNotificationStructure(t *testing.T) {
+
+	test := "test"
+	timeToLive := uint(100)
+	req := PushNotification{
+		Tokens:                []string{"a", "b"},
+		Message:               "Welcome",
+		To:                    test,
+		Priority:              "high",
+		CollapseKey:           "1",
+		ContentAvailable:      true,
+		DelayWhileIdle:        true,
+		TimeToLive:            &timeToLive,
+		RestrictedPackageName: test,
+		DryRun:                true,
+		Title:                 test,
+		Sound:                 test,
+		Data: D{
+			"a": "1",
+			"b": 2,
+		},
+		Notification: AndroidNotification{
+			Color: test,
+			Tag:   test,
+		},
+	}
+
+	notification := GetAndroidNotification(req)
+
+	assert.Equal(t, test, notification.To)
+	assert.Equal(t, "high", notification.Priority)
+	assert.Equal(t, "1", notification.CollapseKey)
+	assert.True(t, notification.ContentAvailable)
+	assert.True(t, notification.DelayWhileIdle)
+	assert.Equal(t, uint(100), *notification.TimeToLive)
+	assert.Equal(t, test, notification.RestrictedPackageName)
+	assert.True(t, notification.DryRun)
+	assert.Equal(t, test, notification.Notification.Title)
+	assert.Equal(t, test, notification.Notification.Sound)
+	assert.Equal(t, test, notification.Notification.Color)
+	assert.Equal(t, test, notification.Notification.Tag)
+	assert.Equal(t, "Welcome", notification.Notification.Body)
+	assert.Equal(t, "1", notification.Data["a"])
+	assert.Equal(t, 2, notification.Data["b"])
+}
+
+func TestAndroidNotificationTransform(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Transform.Rename = map[string]string{"a": "b"}
+	PushConf.Android.Transform.Inject = map[string]string{"app": "legacy"}
+
+	req := PushNotification{
+		Tokens:  []string{"a"},
+		Message: "Welcome",
+		Data: D{
+			"a": "1",
+		},
+	}
+
+	notification := GetAndroidNotification(req)
+
+	assert.Equal(t, "1", notification.Data["b"])
+	assert.Equal(t, "legacy", notification.Data["app"])
+	_, ok := notification.Data["a"]
+	assert.False(t, ok)
+}
+
+func TestGetPreview(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormNtfy,
+		Message:  "Welcome",
+	}
+
+	_, err := GetPreview(req)
+	assert.Equal(t, "preview only support iOS and Android platform", err.Error())
+
+	req.Message = ""
+	_, err = GetPreview(req)
+	assert.Equal(t, "the message must not be empty", err.Error())
+
+	req.Message = "Welcome"
+	req.Platform = PlatFormAndroid
+	req.Data = D{"key": "value"}
+	preview, err := GetPreview(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "android", preview.Platform)
+
+	message := preview.Payload.(gcm.HttpMessage)
+	assert.Equal(t, []string{"aaaaa"}, message.RegistrationIds)
+	assert.Equal(t, "value", message.Data["key"])
+}
+
+func TestMissingAndroidAPIKey(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = ""
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Missing Android API Key", err.Error())
+}
+
+func TestPushToAndroidWrongAPIKey(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY") + "a"
+	InitAppStatus()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaaa", "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	success := PushToAndroid(req)
+	assert.False(t, success)
+	// every token is failed if request to GCM server failed.
+	assert.Equal(t, int64(2), StatStorage.GetAndroidError())
+}
+
+func TestPushToAndroidWrongToken(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaaa", "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	success := PushToAndroid(req)
+	assert.True(t, success)
+}
+
+func TestPushToAndroidRightTokenForJSONLog(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+	// log for json
+	PushConf.Log.Format = "json"
+
+	androidToken := os.Getenv("ANDROID_TEST_TOKEN")
+
+	req := PushNotification{
+		Tokens:   []string{androidToken, "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	success := PushToAndroid(req)
+	assert.True(t, success)
+}
+
+func TestPushToAndroidRightTokenForStringLog(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+
+	androidToken := os.Getenv("ANDROID_TEST_TOKEN")
+
+	req := PushNotification{
+		Tokens:   []string{androidToken, "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	success := PushToAndroid(req)
+	assert.True(t, success)
+}
+
+func TestOverwriteAndroidAPIKey(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+
+	androidToken := os.Getenv("ANDROID_TEST_TOKEN")
+
+	req := PushNotification{
+		Tokens:   []string{androidToken, "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+		// overwrite android api key
+		APIKey: "1234",
+	}
+
+	success := PushToAndroid(req)
+	assert.False(t, success)
+}
+
+func TestCheckAndroidMessage(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+
+	timeToLive := uint(2419201)
+	req := PushNotification{
+		Tokens:     []string{"aaaaaa", "bbbbb"},
+		Platform:   PlatFormAndroid,
+		Message:    "Welcome",
+		TimeToLive: &timeToLive,
+	}
+
+	success := PushToAndroid(req)
+	assert.False(t, success)
+}
+
+func TestPushToAndroidWithChaos(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Mode = "debug"
+	PushConf.Core.Chaos.Enabled = true
+	PushConf.Core.Chaos.DropRate = 1
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+	InitAppStatus()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaaa", "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, PushToAndroid(req))
+	assert.Equal(t, int64(2), StatStorage.GetAndroidError())
+}
+
+func TestTrimAndroidPayload(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Android.TrimPayload = true
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormAndroid,
+		Message:  "Hello",
+		Title:    strings.Repeat("b", 5000),
+		Notification: AndroidNotification{
+			Body: strings.Repeat("a", 3000),
+		},
+	}
+
+	assert.True(t, TrimPayload(&req))
+	assert.Equal(t, "", req.Notification.Body)
+	assert.True(t, strings.HasSuffix(req.Title, ellipsis))
+
+	size, err := payloadSize(req)
+	assert.NoError(t, err)
+	assert.True(t, size <= maxPayloadSize)
+}
+
+func TestPushSingleAndroid(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = os.Getenv("ANDROID_API_KEY")
+	InitAppStatus()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaaa"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	result, err := PushSingle(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "android", result.Platform)
+	assert.False(t, result.Success)
+	assert.NotEmpty(t, result.Reason)
+}
+
+func TestCorrectAndroidConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+
+	err := CheckPushConf()
+
+	assert.NoError(t, err)
+}
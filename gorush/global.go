@@ -2,24 +2,24 @@ package gorush
 
 import (
 	"crypto/tls"
-	"github.com/Sirupsen/logrus"
 	"github.com/appleboy/gorush/config"
-	apns "github.com/sideshow/apns2"
 )
 
 var (
 	// PushConf is gorush config
 	PushConf config.ConfYaml
-	// QueueNotification is chan type
-	QueueNotification chan PushNotification
+	// QueueNotification is queue of notifications
+	QueueNotification Queue
 	// CertificatePemIos is ios certificate file
 	CertificatePemIos tls.Certificate
-	// ApnsClient is apns client
-	ApnsClient *apns.Client
 	// LogAccess is log server request log
-	LogAccess *logrus.Logger
+	LogAccess Logger
 	// LogError is log server error log
-	LogError *logrus.Logger
+	LogError Logger
+	// LogPushIos is iOS push log, nil if not configured
+	LogPushIos Logger
+	// LogPushAndroid is Android push log, nil if not configured
+	LogPushAndroid Logger
 	// StatStorage implements the storage interface
 	StatStorage Storage
 )
@@ -0,0 +1,55 @@
+package gorush
+
+import (
+	"strings"
+)
+
+// GotifyMessage is JSON message of Gotify server.
+// ref: https://gotify.net/docs/pushmsg
+type GotifyMessage struct {
+	Message  string `json:"message"`
+	Title    string `json:"title,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Extras   D      `json:"extras,omitempty"`
+}
+
+// GetGotifyMessage use for define Gotify message.
+func GetGotifyMessage(req PushNotification) GotifyMessage {
+	message := GotifyMessage{
+		Message: req.Message,
+		Title:   req.Title,
+		Extras:  req.Data,
+	}
+
+	if req.Priority.IsHigh() {
+		message.Priority = 8
+	}
+
+	return message
+}
+
+// PushToGotify provide send notification to Gotify server, tokens are application tokens.
+func PushToGotify(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for Gotify")
+
+	var isError bool
+
+	message := GetGotifyMessage(req)
+	url := strings.TrimSuffix(PushConf.Gotify.ServerURL, "/") + "/message"
+
+	for _, token := range req.Tokens {
+		err := postJSON(url, map[string]string{"X-Gotify-Key": token}, message)
+
+		if err != nil {
+			LogPush(FailedPush, token, req, err)
+			StatStorage.AddProviderError("gotify", 1)
+			isError = true
+			continue
+		}
+
+		LogPush(SucceededPush, token, req, nil)
+		StatStorage.AddProviderSuccess("gotify", 1)
+	}
+
+	return isError
+}
@@ -0,0 +1,66 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGotifyMessage(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"AxxxxxX"},
+		Platform: PlatFormGotify,
+		Message:  "Welcome",
+		Title:    "Gorush",
+		Data: D{
+			"key1": "test",
+		},
+	}
+
+	message := GetGotifyMessage(req)
+
+	assert.Equal(t, "Welcome", message.Message)
+	assert.Equal(t, "Gorush", message.Title)
+	assert.Equal(t, 0, message.Priority)
+	assert.Equal(t, "test", message.Extras["key1"])
+}
+
+func TestPushToGotify(t *testing.T) {
+	var path, key string
+	var message GotifyMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		key = r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&message)
+
+		if key == "invalid" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Gotify.Enabled = true
+	InitAppStatus()
+	PushConf.Gotify.ServerURL = ts.URL + "/"
+
+	req := PushNotification{
+		Tokens:   []string{"AxxxxxX"},
+		Platform: PlatFormGotify,
+		Message:  "Welcome",
+		Priority: "high",
+	}
+
+	assert.False(t, PushToGotify(req))
+	assert.Equal(t, "/message", path)
+	assert.Equal(t, "AxxxxxX", key)
+	assert.Equal(t, "Welcome", message.Message)
+	assert.Equal(t, 8, message.Priority)
+
+	req.Tokens = []string{"invalid"}
+	assert.True(t, PushToGotify(req))
+}
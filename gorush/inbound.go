@@ -0,0 +1,122 @@
+package gorush
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// lookupPath return value of dot path in JSON object, e.g. "user.push_tokens".
+func lookupPath(body map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = body
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// mapInbound translate webhook JSON body of external system to notification
+// by field mapping of config, single token string is accepted as tokens.
+func mapInbound(body map[string]interface{}, conf config.SectionInbound) (PushNotification, error) {
+	var notification PushNotification
+
+	fields := map[string]interface{}{
+		"platform": conf.Platform,
+	}
+
+	for field, path := range conf.Mapping {
+		value, ok := lookupPath(body, path)
+		if !ok {
+			continue
+		}
+
+		if token, ok := value.(string); ok && field == "tokens" {
+			value = []string{token}
+		}
+
+		fields[field] = value
+	}
+
+	data, err := json.Marshal(fields)
+
+	if err != nil {
+		return notification, err
+	}
+
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return notification, fmt.Errorf("Can't map webhook body to notification: %s", err.Error())
+	}
+
+	return notification, nil
+}
+
+func inboundHandler(c *gin.Context) {
+	var body map[string]interface{}
+	var msg string
+
+	secret := PushConf.Inbound.Secret
+	if secret != "" && subtle.ConstantTimeCompare([]byte(c.Request.Header.Get("X-Webhook-Secret")), []byte(secret)) != 1 {
+		msg = "Wrong webhook secret."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusUnauthorized, msg)
+		return
+	}
+
+	data, err := ioutil.ReadAll(c.Request.Body)
+
+	if err != nil || json.Unmarshal(data, &body) != nil {
+		msg = "Webhook body must be JSON object."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	notification, err := mapInbound(body, PushConf.Inbound)
+
+	if err != nil {
+		LogAccess.Debug(err.Error())
+		abortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	notifications, invalidTokens, code, err := validateNotification(0, notification)
+
+	if err != nil {
+		LogAccess.Debug(err.Error())
+		abortWithMessageError(c, code, err)
+		return
+	}
+
+	requestID, traceParent := traceHeaders(c)
+	for i := range notifications {
+		notifications[i].RequestID = requestID
+		notifications[i].TraceParent = traceParent
+	}
+
+	queueNotificationAsync(RequestPush{
+		Notifications: notifications,
+	})
+
+	result := gin.H{
+		"success": "ok",
+	}
+
+	if len(invalidTokens) > 0 {
+		result["invalid_tokens"] = invalidTokens
+	}
+
+	c.JSON(http.StatusOK, result)
+}
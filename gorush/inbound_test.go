@@ -0,0 +1,121 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/appleboy/gofight.v1"
+	"net/http"
+	"testing"
+)
+
+func TestLookupPath(t *testing.T) {
+	body := map[string]interface{}{
+		"user": map[string]interface{}{
+			"token": "aaaaa",
+		},
+		"text": "Welcome",
+	}
+
+	value, ok := lookupPath(body, "user.token")
+	assert.True(t, ok)
+	assert.Equal(t, "aaaaa", value)
+
+	value, ok = lookupPath(body, "text")
+	assert.True(t, ok)
+	assert.Equal(t, "Welcome", value)
+
+	_, ok = lookupPath(body, "user.name")
+	assert.False(t, ok)
+
+	_, ok = lookupPath(body, "text.value")
+	assert.False(t, ok)
+}
+
+func TestMapInbound(t *testing.T) {
+	conf := config.SectionInbound{
+		Platform: PlatFormAndroid,
+		Mapping: map[string]string{
+			"tokens":  "user.token",
+			"message": "campaign.body",
+			"title":   "campaign.title",
+			"data":    "campaign.extras",
+		},
+	}
+
+	body := map[string]interface{}{
+		"user": map[string]interface{}{
+			"token": "aaaaa",
+		},
+		"campaign": map[string]interface{}{
+			"body": "Welcome",
+			"extras": map[string]interface{}{
+				"campaign_id": "summer",
+			},
+		},
+	}
+
+	notification, err := mapInbound(body, conf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aaaaa"}, notification.Tokens)
+	assert.Equal(t, PlatFormAndroid, notification.Platform)
+	assert.Equal(t, "Welcome", notification.Message)
+	assert.Equal(t, "", notification.Title)
+	assert.Equal(t, "summer", notification.Data["campaign_id"])
+
+	// wrong type of field
+	body["campaign"].(map[string]interface{})["body"] = 123
+	_, err = mapInbound(body, conf)
+	assert.Error(t, err)
+}
+
+func TestInboundHandler(t *testing.T) {
+	initTest()
+	InitLog()
+
+	PushConf.Inbound.Enabled = true
+	PushConf.Inbound.Secret = "secret"
+
+	r := gofight.New()
+
+	r.POST("/api/inbound").
+		SetJSON(gofight.D{
+			"tokens":   "aaaaa",
+			"platform": PlatFormIos,
+			"message":  "Welcome",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Wrong webhook secret.", value)
+			assert.Equal(t, http.StatusUnauthorized, r.Code)
+		})
+
+	r.POST("/api/inbound").
+		SetHeader(gofight.H{
+			"X-Webhook-Secret": "secret",
+		}).
+		SetJSON(gofight.D{
+			"tokens":   "aaaaa",
+			"platform": PlatFormIos,
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "error_code")
+
+			assert.Equal(t, ErrCodeEmptyMessage, value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+
+	r.POST("/api/inbound").
+		SetHeader(gofight.H{
+			"X-Webhook-Secret": "secret",
+		}).
+		SetJSON(gofight.D{
+			"tokens":   "aaaaa",
+			"platform": PlatFormIos,
+			"message":  "Welcome",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
@@ -0,0 +1,51 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+)
+
+// maxTokensForPlatForm return max tokens of a notification, platform config overwrite core config.
+func maxTokensForPlatForm(platform int) int {
+	switch {
+	case platform == PlatFormIos && PushConf.Ios.MaxTokens > 0:
+		return PushConf.Ios.MaxTokens
+	case platform == PlatFormAndroid && PushConf.Android.MaxTokens > 0:
+		return PushConf.Android.MaxTokens
+	default:
+		return PushConf.Core.MaxTokens
+	}
+}
+
+// maxDataSizeForPlatForm return max bytes of data field, platform config overwrite core config.
+func maxDataSizeForPlatForm(platform int) int {
+	switch {
+	case platform == PlatFormIos && PushConf.Ios.MaxDataSize > 0:
+		return PushConf.Ios.MaxDataSize
+	case platform == PlatFormAndroid && PushConf.Android.MaxDataSize > 0:
+		return PushConf.Android.MaxDataSize
+	default:
+		return PushConf.Core.MaxDataSize
+	}
+}
+
+// CheckLimit check tokens and data size of notification before queueing.
+func CheckLimit(req PushNotification) error {
+	if max := maxTokensForPlatForm(req.Platform); max > 0 && len(req.Tokens) > max {
+		return newMessageError(ErrCodeTooManyTokens, "tokens", fmt.Sprintf("number of tokens(%d) over limit(%d)", len(req.Tokens), max))
+	}
+
+	if max := maxDataSizeForPlatForm(req.Platform); max > 0 && len(req.Data) > 0 {
+		data, err := json.Marshal(req.Data)
+
+		if err != nil {
+			return newMessageError(ErrCodeInvalidData, "data", err.Error())
+		}
+
+		if len(data) > max {
+			return newMessageError(ErrCodeDataTooLarge, "data", fmt.Sprintf("size of data(%d) over limit(%d)", len(data), max))
+		}
+	}
+
+	return nil
+}
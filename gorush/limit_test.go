@@ -0,0 +1,44 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckLimit(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+
+	req := PushNotification{
+		Tokens:   []string{"aaa", "bbb", "ccc"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+		Data: D{
+			"key": "value",
+		},
+	}
+
+	// unlimited
+	assert.NoError(t, CheckLimit(req))
+
+	PushConf.Core.MaxTokens = 2
+	err := CheckLimit(req)
+	assert.Equal(t, "number of tokens(3) over limit(2)", err.Error())
+	assert.Equal(t, ErrCodeTooManyTokens, err.(*MessageError).Code)
+
+	// platform config overwrite core config
+	PushConf.Android.MaxTokens = 3
+	assert.NoError(t, CheckLimit(req))
+
+	PushConf.Core.MaxDataSize = 10
+	err = CheckLimit(req)
+	assert.Equal(t, "size of data(15) over limit(10)", err.Error())
+	assert.Equal(t, ErrCodeDataTooLarge, err.(*MessageError).Code)
+
+	PushConf.Ios.MaxDataSize = 100
+	assert.Equal(t, "size of data(15) over limit(10)", CheckLimit(req).Error())
+
+	PushConf.Android.MaxDataSize = 15
+	assert.NoError(t, CheckLimit(req))
+}
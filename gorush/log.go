@@ -1,10 +1,13 @@
 package gorush
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/Sirupsen/logrus"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
 	"github.com/gin-gonic/gin"
 	"os"
 	"strings"
@@ -32,11 +35,18 @@ type LogReq struct {
 
 // LogPushEntry is push response log
 type LogPushEntry struct {
-	Type     string `json:"type"`
-	Platform string `json:"platform"`
-	Token    string `json:"token"`
-	Message  string `json:"message"`
-	Error    string `json:"error"`
+	Type      string `json:"type"`
+	Platform  string `json:"platform"`
+	Token     string `json:"token"`
+	Message   string `json:"message"`
+	Error     string `json:"error"`
+	Ref       string `json:"ref,omitempty"`
+	Variant   string `json:"variant,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+
+	// Tracing
+	RequestID   string `json:"request_id,omitempty"`
+	TraceParent string `json:"traceparent,omitempty"`
 
 	// Android
 	To                    string `json:"to,omitempty"`
@@ -56,43 +66,52 @@ type LogPushEntry struct {
 
 // InitLog use for initial log module
 func InitLog() error {
+	factory, ok := loggerFactory(PushConf.Log.Engine)
+	if !ok {
+		return errors.New("Unknown log engine " + PushConf.Log.Engine)
+	}
 
-	var err error
-
-	// init logger
-	LogAccess = logrus.New()
-	LogError = logrus.New()
+	// keep previous logger on error, so the error can still be logged.
+	access, err := factory(PushConf.Log.AccessLog, PushConf.Log.AccessLevel)
+	if err != nil {
+		return errors.New("Set access log error: " + err.Error())
+	}
+	LogAccess = access
 
-	LogAccess.Formatter = &logrus.TextFormatter{
-		TimestampFormat: "2006/01/02 - 15:04:05",
-		ForceColors:     true,
-		FullTimestamp:   true,
+	errorLog, err := factory(PushConf.Log.ErrorLog, PushConf.Log.ErrorLevel)
+	if err != nil {
+		return errors.New("Set error log error: " + err.Error())
 	}
+	LogError = errorLog
 
-	LogError.Formatter = &logrus.TextFormatter{
-		TimestampFormat: "2006/01/02 - 15:04:05",
-		ForceColors:     true,
-		FullTimestamp:   true,
+	if LogPushIos, err = newPushLog(factory, PushConf.Ios.Log); err != nil {
+		return errors.New("Set iOS push log error: " + err.Error())
 	}
 
-	// set logger
-	if err := SetLogLevel(LogAccess, PushConf.Log.AccessLevel); err != nil {
-		return errors.New("Set access log level error: " + err.Error())
+	if LogPushAndroid, err = newPushLog(factory, PushConf.Android.Log); err != nil {
+		return errors.New("Set Android push log error: " + err.Error())
 	}
 
-	if err := SetLogLevel(LogError, PushConf.Log.ErrorLevel); err != nil {
-		return errors.New("Set error log level error: " + err.Error())
+	return nil
+}
+
+// newPushLog create push logger of platform, return nil if path is empty.
+func newPushLog(factory LoggerFactory, conf config.SectionPushLog) (Logger, error) {
+	if conf.Path == "" {
+		return nil, nil
 	}
 
-	if err = SetLogOut(LogAccess, PushConf.Log.AccessLog); err != nil {
-		return errors.New("Set access log path error: " + err.Error())
+	if PushConf.Log.Engine != "logrus" {
+		return factory(conf.Path, conf.Level)
 	}
 
-	if err = SetLogOut(LogError, PushConf.Log.ErrorLog); err != nil {
-		return errors.New("Set error log path error: " + err.Error())
+	log, err := newLogrusLogger(conf.Path, conf.Level, false)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return log, nil
 }
 
 // SetLogOut provide log stdout and stderr output
@@ -165,6 +184,10 @@ func colorForPlatForm(platform int) string {
 		return blue
 	case PlatFormAndroid:
 		return yellow
+	case PlatFormNtfy, PlatFormGotify, PlatFormTelegram:
+		return cyan
+	case PlatFormSlack, PlatFormTeams:
+		return magenta
 	default:
 		return reset
 	}
@@ -176,11 +199,43 @@ func typeForPlatForm(platform int) string {
 		return "ios"
 	case PlatFormAndroid:
 		return "android"
+	case PlatFormNtfy:
+		return "ntfy"
+	case PlatFormGotify:
+		return "gotify"
+	case PlatFormTelegram:
+		return "telegram"
+	case PlatFormSlack:
+		return "slack"
+	case PlatFormTeams:
+		return "teams"
 	default:
 		return ""
 	}
 }
 
+func pushLogForPlatForm(platform int) Logger {
+	switch platform {
+	case PlatFormIos:
+		return LogPushIos
+	case PlatFormAndroid:
+		return LogPushAndroid
+	default:
+		return nil
+	}
+}
+
+func pushLogFormatForPlatForm(platform int) string {
+	switch platform {
+	case PlatFormIos:
+		return PushConf.Ios.Log.Format
+	case PlatFormAndroid:
+		return PushConf.Android.Log.Format
+	default:
+		return PushConf.Log.Format
+	}
+}
+
 func hideToken(token string, markLen int) string {
 	if len(token) == 0 {
 		return ""
@@ -199,6 +254,58 @@ func hideToken(token string, markLen int) string {
 	return result
 }
 
+// logToken hide token in log output if hide_token is enabled and token is not hashed.
+func logToken(token string) string {
+	if PushConf.Log.HideToken == true && PushConf.Log.HashToken == false {
+		return hideToken(token, 10)
+	}
+
+	return token
+}
+
+// hashSaltOf return salt of hash_token for app, salt of iOS certs is selected by
+// topic, salt of platform and log config are used as default.
+func hashSaltOf(req PushNotification) string {
+	switch req.Platform {
+	case PlatFormIos:
+		for _, cert := range PushConf.Ios.Certs {
+			if cert.Topic == req.Topic && cert.HashSalt != "" {
+				return cert.HashSalt
+			}
+		}
+
+		if PushConf.Ios.HashSalt != "" {
+			return PushConf.Ios.HashSalt
+		}
+	case PlatFormAndroid:
+		if PushConf.Android.HashSalt != "" {
+			return PushConf.Android.HashSalt
+		}
+	}
+
+	return PushConf.Log.HashSalt
+}
+
+// maskToken replace token with hash if hash_token is enabled, raw token is only
+// kept in memory for sending.
+func maskToken(token string, req PushNotification) string {
+	if PushConf.Log.HashToken == true {
+		return hashToken(token, hashSaltOf(req))
+	}
+
+	return token
+}
+
+func hashToken(token, salt string) string {
+	if len(token) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(salt + token))
+
+	return hex.EncodeToString(sum[:])
+}
+
 // LogPush record user push request and server response.
 func LogPush(status, token string, req PushNotification, errPush error) {
 	var plat, platColor, output string
@@ -206,24 +313,53 @@ func LogPush(status, token string, req PushNotification, errPush error) {
 	platColor = colorForPlatForm(req.Platform)
 	plat = typeForPlatForm(req.Platform)
 
+	format := PushConf.Log.Format
+	logPush := pushLogForPlatForm(req.Platform)
+	if logPush != nil {
+		format = pushLogFormatForPlatForm(req.Platform)
+	}
+
 	errMsg := ""
 	if errPush != nil {
 		errMsg = errPush.Error()
 	}
 
-	if PushConf.Log.HideToken == true {
-		token = hideToken(token, 10)
-	}
+	// correlation ID of caller
+	ref := req.Refs[token]
+
+	token = maskToken(token, req)
 
 	log := &LogPushEntry{
-		Type:     status,
-		Platform: plat,
-		Token:    token,
-		Message:  req.Message,
-		Error:    errMsg,
+		Type:      status,
+		Platform:  plat,
+		Token:     token,
+		Message:   req.Message,
+		Error:     errMsg,
+		Ref:       ref,
+		Variant:   req.Variant,
+		Truncated: req.Truncated,
+
+		RequestID:   req.RequestID,
+		TraceParent: req.TraceParent,
 	}
 
-	if PushConf.Log.Format == "json" {
+	if req.Platform == PlatFormIos {
+		log.ApnsID = req.ApnsID
+	}
+
+	queueCallback(*log)
+	if status == SucceededPush {
+		publishEvent(EventSent, token, req, 0, nil)
+	} else {
+		publishEvent(EventFailed, token, req, 0, errPush)
+	}
+	addVariantStat(req.RequestID, req.Variant, status)
+	addSummary(req.RequestID, status, errPush)
+
+	// callbacks and events keep the token, it is only hidden in log output.
+	log.Token = logToken(token)
+
+	if format == "json" {
 		logJSON, _ := json.Marshal(log)
 
 		output = string(logJSON)
@@ -236,6 +372,9 @@ func LogPush(status, token string, req PushNotification, errPush error) {
 				log.Token,
 				log.Message,
 			)
+			if log.ApnsID != "" {
+				output += " | apns-id: " + log.ApnsID
+			}
 		case FailedPush:
 			output = fmt.Sprintf("|%s %s %s| %s%s%s [%s] | %s | Error Message: %s",
 				red, log.Type, reset,
@@ -245,13 +384,33 @@ func LogPush(status, token string, req PushNotification, errPush error) {
 				log.Error,
 			)
 		}
+
+		if log.Ref != "" {
+			output += " | ref: " + log.Ref
+		}
+
+		if log.Variant != "" {
+			output += " | variant: " + log.Variant
+		}
+
+		if log.RequestID != "" {
+			output += " | request-id: " + log.RequestID
+		}
 	}
 
 	switch status {
 	case SucceededPush:
-		LogAccess.Info(string(output))
+		if logPush != nil {
+			logPush.Info(string(output))
+		} else {
+			LogAccess.Info(string(output))
+		}
 	case FailedPush:
-		LogError.Error(string(output))
+		if logPush != nil {
+			logPush.Error(string(output))
+		} else {
+			LogError.Error(string(output))
+		}
 	}
 }
 
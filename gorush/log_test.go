@@ -1,6 +1,7 @@
 package gorush
 
 import (
+	"errors"
 	"github.com/Sirupsen/logrus"
 	"github.com/appleboy/gorush/config"
 	"github.com/stretchr/testify/assert"
@@ -77,15 +78,48 @@ func TestErrorLogPath(t *testing.T) {
 	assert.NotNil(t, InitLog())
 }
 
+func TestPushLogPath(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	assert.Nil(t, InitLog())
+	assert.Nil(t, LogPushIos)
+	assert.Nil(t, LogPushAndroid)
+
+	PushConf.Ios.Log.Path = "log/ios.log"
+
+	assert.Nil(t, InitLog())
+	assert.NotNil(t, LogPushIos)
+	assert.Nil(t, LogPushAndroid)
+
+	PushConf.Android.Log.Path = "logs/android.log"
+
+	assert.NotNil(t, InitLog())
+}
+
+func TestPushLogLevel(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Log.Path = "stdout"
+	PushConf.Android.Log.Level = "invalid"
+
+	assert.NotNil(t, InitLog())
+}
+
 func TestPlatFormType(t *testing.T) {
 	assert.Equal(t, "ios", typeForPlatForm(PlatFormIos))
 	assert.Equal(t, "android", typeForPlatForm(PlatFormAndroid))
+	assert.Equal(t, "ntfy", typeForPlatForm(PlatFormNtfy))
+	assert.Equal(t, "gotify", typeForPlatForm(PlatFormGotify))
+	assert.Equal(t, "telegram", typeForPlatForm(PlatFormTelegram))
+	assert.Equal(t, "slack", typeForPlatForm(PlatFormSlack))
+	assert.Equal(t, "teams", typeForPlatForm(PlatFormTeams))
 	assert.Equal(t, "", typeForPlatForm(10000))
 }
 
 func TestPlatFormColor(t *testing.T) {
 	assert.Equal(t, blue, colorForPlatForm(PlatFormIos))
 	assert.Equal(t, yellow, colorForPlatForm(PlatFormAndroid))
+	assert.Equal(t, cyan, colorForPlatForm(PlatFormNtfy))
 	assert.Equal(t, reset, colorForPlatForm(1000000))
 }
 
@@ -94,3 +128,136 @@ func TestHideToken(t *testing.T) {
 	assert.Equal(t, "**345678**", hideToken("1234567890", 2))
 	assert.Equal(t, "*****", hideToken("12345", 10))
 }
+
+func TestHashToken(t *testing.T) {
+	assert.Equal(t, "", hashToken("", "salt"))
+	assert.Equal(t, 64, len(hashToken("1234567890", "")))
+	assert.Equal(t, hashToken("1234567890", "salt"), hashToken("1234567890", "salt"))
+	assert.NotEqual(t, hashToken("1234567890", "salt"), hashToken("1234567890", "pepper"))
+}
+
+func TestHashSaltOf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Log.HashToken = true
+	PushConf.Log.HashSalt = "global"
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.app", HashSalt: "app"},
+	}
+
+	req := PushNotification{
+		Platform: PlatFormIos,
+		Topic:    "com.example.other",
+	}
+
+	assert.Equal(t, "global", hashSaltOf(req))
+
+	PushConf.Ios.HashSalt = "ios"
+	assert.Equal(t, "ios", hashSaltOf(req))
+
+	req.Topic = "com.example.app"
+	assert.Equal(t, "app", hashSaltOf(req))
+	assert.Equal(t, hashToken("aaaaa", "app"), maskToken("aaaaa", req))
+
+	req.Platform = PlatFormAndroid
+	assert.Equal(t, "global", hashSaltOf(req))
+
+	PushConf.Android.HashSalt = "android"
+	assert.Equal(t, "android", hashSaltOf(req))
+
+	PushConf.Log.HashToken = false
+	assert.Equal(t, "aaaaa", maskToken("aaaaa", req))
+}
+
+func TestLogPushRef(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Log.HashToken = true
+	InitLog()
+
+	callbackQueue = make(chan LogPushEntry, 2)
+	defer func() {
+		callbackQueue = nil
+	}()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa", "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+		Refs: map[string]string{
+			"aaaaa": "message-1",
+		},
+	}
+
+	LogPush(FailedPush, "aaaaa", req, errors.New("InvalidRegistration"))
+	LogPush(SucceededPush, "bbbbb", req, nil)
+
+	entry := <-callbackQueue
+	assert.Equal(t, "message-1", entry.Ref)
+	assert.Equal(t, hashToken("aaaaa", ""), entry.Token)
+
+	entry = <-callbackQueue
+	assert.Equal(t, "", entry.Ref)
+
+	// hidden token is only hidden in log output.
+	PushConf.Log.HashToken = false
+	PushConf.Log.HideToken = true
+
+	LogPush(FailedPush, "aaaaa", req, errors.New("InvalidRegistration"))
+
+	entry = <-callbackQueue
+	assert.Equal(t, "aaaaa", entry.Token)
+}
+
+func TestLogPushRequestID(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+
+	callbackQueue = make(chan LogPushEntry, 1)
+	defer func() {
+		callbackQueue = nil
+	}()
+
+	req := PushNotification{
+		Tokens:      []string{"aaaaa"},
+		Platform:    PlatFormAndroid,
+		Message:     "Welcome",
+		RequestID:   "request-1",
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+
+	LogPush(SucceededPush, "aaaaa", req, nil)
+
+	entry := <-callbackQueue
+	assert.Equal(t, "request-1", entry.RequestID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", entry.TraceParent)
+}
+
+type testLogger struct {
+	noopLogger
+	output string
+}
+
+func TestLogEngine(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Log.Engine = "unknown"
+	assert.NotNil(t, InitLog())
+
+	PushConf.Log.Engine = "noop"
+	assert.Nil(t, InitLog())
+	assert.Equal(t, noopLogger{}, LogAccess)
+
+	RegisterLogger("test", func(output, level string) (Logger, error) {
+		return &testLogger{output: output}, nil
+	})
+
+	PushConf.Log.Engine = "test"
+	PushConf.Ios.Log.Path = "ios.log"
+	assert.Nil(t, InitLog())
+	assert.Equal(t, "stdout", LogAccess.(*testLogger).output)
+	assert.Equal(t, "stderr", LogError.(*testLogger).output)
+	assert.Equal(t, "ios.log", LogPushIos.(*testLogger).output)
+	assert.Nil(t, LogPushAndroid)
+
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+}
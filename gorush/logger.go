@@ -0,0 +1,80 @@
+package gorush
+
+import (
+	"github.com/Sirupsen/logrus"
+	"os"
+	"sync"
+)
+
+// Logger is logging backend of access, error and push logs.
+type Logger interface {
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+	Fatal(args ...interface{})
+}
+
+// LoggerFactory create logger writing to output (stdout, stderr or file path)
+// with minimum level (debug, info, warn, error, fatal or panic).
+type LoggerFactory func(output, level string) (Logger, error)
+
+var (
+	loggersLock sync.RWMutex
+	loggers     = map[string]LoggerFactory{
+		"logrus": func(output, level string) (Logger, error) {
+			return newLogrusLogger(output, level, true)
+		},
+		"noop": func(output, level string) (Logger, error) {
+			return noopLogger{}, nil
+		},
+	}
+)
+
+// RegisterLogger add custom logging backend (e.g. zap or zerolog adapter) which
+// can be selected by name in log engine config. Register the same name again will replace it.
+func RegisterLogger(name string, factory LoggerFactory) {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	loggers[name] = factory
+}
+
+// loggerFactory return factory of log engine.
+func loggerFactory(name string) (LoggerFactory, bool) {
+	loggersLock.RLock()
+	defer loggersLock.RUnlock()
+
+	factory, ok := loggers[name]
+
+	return factory, ok
+}
+
+// newLogrusLogger create logrus logger, colors is forced for console output of access and error log.
+func newLogrusLogger(output, level string, colors bool) (*logrus.Logger, error) {
+	log := logrus.New()
+	log.Formatter = &logrus.TextFormatter{
+		TimestampFormat: "2006/01/02 - 15:04:05",
+		ForceColors:     colors,
+		FullTimestamp:   true,
+	}
+
+	if err := SetLogLevel(log, level); err != nil {
+		return nil, err
+	}
+
+	if err := SetLogOut(log, output); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// noopLogger discard all logs, Fatal still exit the program.
+type noopLogger struct{}
+
+func (noopLogger) Debug(args ...interface{}) {}
+func (noopLogger) Info(args ...interface{})  {}
+func (noopLogger) Warn(args ...interface{})  {}
+func (noopLogger) Error(args ...interface{}) {}
+func (noopLogger) Fatal(args ...interface{}) { os.Exit(1) }
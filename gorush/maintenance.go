@@ -0,0 +1,259 @@
+package gorush
+
+import (
+	"github.com/fvbock/endless"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// maintenanceWait is interval of worker to check the maintenance mode.
+var maintenanceWait = 100 * time.Millisecond
+
+var maintenance int32
+
+// working is number of notifications dequeued and not yet acked by workers.
+var working int64
+
+// queueing is number of requests accepted and not yet queued.
+var queueing int64
+
+// RequestMaintenance is request of maintenance mode.
+type RequestMaintenance struct {
+	Enabled bool `json:"enabled"`
+	// Export undelivered notifications to queue_export_path.
+	Export bool `json:"export"`
+}
+
+// MaintenanceStatus is response of maintenance mode.
+type MaintenanceStatus struct {
+	Maintenance bool `json:"maintenance"`
+	QueueUsage  int  `json:"queue_usage"`
+	Exported    int  `json:"exported"`
+}
+
+// SetMaintenance stop or resume dequeuing notifications.
+func SetMaintenance(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&maintenance, 1)
+		return
+	}
+
+	atomic.StoreInt32(&maintenance, 0)
+}
+
+// IsMaintenance return true if server is in maintenance mode.
+func IsMaintenance() bool {
+	return atomic.LoadInt32(&maintenance) == 1
+}
+
+// waitMaintenance block worker while server is in maintenance mode.
+func waitMaintenance() {
+	for IsMaintenance() {
+		time.Sleep(maintenanceWait)
+	}
+}
+
+// ExportQueue drain undelivered notifications of queue into file, one JSON per line.
+// Notifications are put back into queue if they can't be written.
+func ExportQueue(path string) (int, error) {
+	notifications, err := SnapshotQueue()
+
+	if err == nil {
+		err = appendSnapshot(path, notifications)
+	}
+
+	if err != nil {
+		RestoreQueue(notifications)
+		return 0, err
+	}
+
+	return len(notifications), nil
+}
+
+// queueNotificationAsync queue notifications of request in background,
+// DrainQueue waits for it before server exits.
+func queueNotificationAsync(req RequestPush) {
+	atomic.AddInt64(&queueing, 1)
+	go func() {
+		defer atomic.AddInt64(&queueing, -1)
+		queueNotification(req)
+	}()
+}
+
+// DrainQueue wait workers to deliver queued notifications before server exits,
+// the rest are exported to queue_export_path after timeout. Notifications of
+// batch windows are queued at once, and ones held by quiet hours or disabled
+// apps are exported.
+func DrainQueue(timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&queueing) > 0 && time.Now().Before(deadline) {
+		time.Sleep(maintenanceWait)
+	}
+
+	flushBatches()
+
+	for QueueNotification.Len() > 0 || atomic.LoadInt64(&working) > 0 {
+		if time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(maintenanceWait)
+	}
+
+	held := append(releaseQuiet(), releaseParked()...)
+	if QueueNotification.Len() == 0 && len(held) == 0 {
+		return 0, nil
+	}
+
+	SetMaintenance(true)
+	count, err := ExportQueue(PushConf.Core.QueueExportPath)
+
+	if err != nil || len(held) == 0 {
+		return count, err
+	}
+
+	if err := appendSnapshot(PushConf.Core.QueueExportPath, held); err != nil {
+		return count, err
+	}
+
+	return count + len(held), nil
+}
+
+// handoverPath is sentinel file of restart, it exists while old process
+// drains its queue into queue_export_path.
+func handoverPath() string {
+	return PushConf.Core.QueueExportPath + ".handover"
+}
+
+// beginHandover create sentinel file before new process is started on SIGHUP.
+func beginHandover() {
+	if err := ioutil.WriteFile(handoverPath(), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		LogError.Error("Can't create handover file: ", err)
+	}
+}
+
+// EndHandover remove sentinel file after old process exported its queue.
+func EndHandover() {
+	os.Remove(handoverPath())
+}
+
+// WaitHandover block new process until old process exported its queue on
+// restart. Old process finishes requests in hammer time of endless and drains
+// queue in drain_timeout, the sentinel file is removed after both if old process died.
+func WaitHandover() {
+	deadline := time.Now().Add(endless.DefaultHammerTime + time.Duration(PushConf.Core.DrainTimeout)*time.Second)
+	for {
+		if _, err := os.Stat(handoverPath()); os.IsNotExist(err) {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			LogError.Error("old process didn't export queue in time, remove ", handoverPath())
+			EndHandover()
+			return
+		}
+
+		time.Sleep(maintenanceWait)
+	}
+}
+
+// ImportQueue queue notifications exported to file on last exit and remove the file.
+// Notifications are queued in batches as workers free space of queue, and the rest
+// are kept in file if queue stays full for drain_timeout or they can't be queued.
+func ImportQueue(path string) (int, error) {
+	file, err := os.Open(path)
+
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	notifications, err := ReadSnapshot(file)
+	file.Close()
+
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	timeout := time.Duration(PushConf.Core.DrainTimeout) * time.Second
+	deadline := time.Now().Add(timeout)
+	for {
+		n, err := RestoreQueue(notifications[count:])
+		count += n
+
+		if err == nil {
+			return count, os.Remove(path)
+		}
+
+		if n > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+
+		if err != errQueueFull || time.Now().After(deadline) {
+			if count > 0 {
+				if werr := writeSnapshotFile(path, notifications[count:]); werr != nil {
+					LogError.Error("Can't keep notifications not imported: ", werr)
+				}
+			}
+
+			return count, err
+		}
+
+		time.Sleep(maintenanceWait)
+	}
+}
+
+func maintenanceHandler(c *gin.Context) {
+	var form RequestMaintenance
+	var msg string
+
+	if err := c.BindWith(&form, jsonBinding{}); err != nil {
+		msg = "Missing enabled field."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	SetMaintenance(form.Enabled)
+	LogAccess.Info("set maintenance mode: ", form.Enabled)
+
+	result := MaintenanceStatus{
+		Maintenance: form.Enabled,
+	}
+
+	if form.Export {
+		if !form.Enabled {
+			msg = "Export is only allowed in maintenance mode."
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusBadRequest, msg)
+			return
+		}
+
+		count, err := ExportQueue(PushConf.Core.QueueExportPath)
+		result.Exported = count
+
+		if err != nil {
+			msg = "Can't export queue: " + err.Error()
+			LogError.Error(msg)
+			abortWithError(c, http.StatusInternalServerError, msg)
+			return
+		}
+
+		LogAccess.Info("export ", count, " notifications to ", PushConf.Core.QueueExportPath)
+	}
+
+	if QueueNotification != nil {
+		result.QueueUsage = QueueNotification.Len()
+	}
+
+	c.JSON(http.StatusOK, result)
+}
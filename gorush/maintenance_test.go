@@ -0,0 +1,202 @@
+package gorush
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/fvbock/endless"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	assert.False(t, IsMaintenance())
+
+	SetMaintenance(true)
+	assert.True(t, IsMaintenance())
+
+	done := make(chan bool)
+	go func() {
+		waitMaintenance()
+		done <- true
+	}()
+
+	SetMaintenance(false)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("worker is not resumed")
+	}
+}
+
+func TestExportQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	QueueNotification = newChannelQueue(10, 0)
+
+	path := "gorush-queue-test.json"
+	defer os.Remove(path)
+
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"})
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"bbbbb"}, Platform: PlatFormAndroid, Message: "World"})
+
+	count, err := ExportQueue(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification PushNotification
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &notification))
+		messages = append(messages, notification.Message)
+	}
+	assert.Equal(t, []string{"Hello", "World"}, messages)
+}
+
+func TestDrainQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.QueueExportPath = "gorush-drain-test.json"
+	defer os.Remove(PushConf.Core.QueueExportPath)
+	defer SetMaintenance(false)
+
+	QueueNotification = newChannelQueue(10, 0)
+	count, err := DrainQueue(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.False(t, IsMaintenance())
+
+	// no worker is running, notifications are exported after timeout.
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"})
+	count, err = DrainQueue(200 * time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, IsMaintenance())
+	assert.Equal(t, 0, QueueNotification.Len())
+}
+
+func TestDrainHeldNotifications(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.QueueExportPath = "gorush-drain-held-test.json"
+	defer os.Remove(PushConf.Core.QueueExportPath)
+	defer SetMaintenance(false)
+	InitLog()
+	InitAppStatus()
+	InitApps()
+
+	QueueNotification = newChannelQueue(10, 0)
+	SetApp("com.example.app", false, true)
+	defer SetApp("com.example.app", true, false)
+	parkNotification(PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Topic: "com.example.app", Message: "Hello"})
+
+	// parked notification is exported though queue is empty.
+	count, err := DrainQueue(100 * time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	file, err := os.Open(PushConf.Core.QueueExportPath)
+	assert.NoError(t, err)
+	notifications, err := ReadSnapshot(file)
+	file.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "com.example.app", notifications[0].Topic)
+}
+
+func TestImportQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	QueueNotification = newChannelQueue(10, 0)
+	path := "gorush-import-test.json"
+	defer os.Remove(path)
+
+	// nothing was exported.
+	count, err := ImportQueue(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	file, _ := os.Create(path)
+	WriteSnapshot(file, []PushNotification{
+		{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"},
+		{Tokens: []string{"bbbbb"}, Platform: PlatFormAndroid, Message: "Hello"},
+	})
+	file.Close()
+
+	count, err = ImportQueue(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, QueueNotification.Len())
+
+	// file is removed after import.
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// the rest is kept in file if queue is full.
+	PushConf.Core.QueueNum = 3
+	PushConf.Core.DrainTimeout = 0
+
+	file, _ = os.Create(path)
+	WriteSnapshot(file, []PushNotification{
+		{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"},
+		{Tokens: []string{"bbbbb"}, Platform: PlatFormAndroid, Message: "World"},
+	})
+	file.Close()
+
+	count, err = ImportQueue(path)
+	assert.Equal(t, errQueueFull, err)
+	assert.Equal(t, 1, count)
+
+	file, _ = os.Open(path)
+	notifications, _ := ReadSnapshot(file)
+	file.Close()
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, "World", notifications[0].Message)
+}
+
+func TestHandover(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.QueueExportPath = "gorush-handover-test.json"
+	InitLog()
+
+	// no restart in progress
+	WaitHandover()
+
+	beginHandover()
+	_, err := os.Stat(handoverPath())
+	assert.NoError(t, err)
+
+	done := make(chan bool)
+	go func() {
+		WaitHandover()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+		t.Error("new process doesn't wait for export of old process")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	EndHandover()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("new process is not released after export")
+	}
+
+	// sentinel of dead process is removed after timeout
+	hammer := endless.DefaultHammerTime
+	endless.DefaultHammerTime = 0
+	defer func() { endless.DefaultHammerTime = hammer }()
+	PushConf.Core.DrainTimeout = 0
+
+	beginHandover()
+	WaitHandover()
+	_, err = os.Stat(handoverPath())
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,21 +1,35 @@
 package gorush
 
 import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
-	"github.com/google/go-gcm"
-	apns "github.com/sideshow/apns2"
-	"github.com/sideshow/apns2/certificate"
-	"github.com/sideshow/apns2/payload"
+	"github.com/appleboy/gorush/config"
+	netproxy "golang.org/x/net/proxy"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf16"
 )
 
 // D provide string array
 type D map[string]interface{}
 
+const (
+	// maxDataKeyLength is lint limit of data key length.
+	maxDataKeyLength = 64
+	// maxMessageLength is lint limit of message length shown on device.
+	maxMessageLength = 178
+)
+
 const (
 	// ApnsPriorityLow will tell APNs to send the push message at a time that takes
 	// into account power considerations for the device. Notifications with this
@@ -28,6 +42,11 @@ const (
 	// the target device. It is an error to use this priority for a push
 	// notification that contains only the content-available key.
 	ApnsPriorityHigh = 10
+
+	// ApnsPriorityPower will tell APNs to prioritize the device's power
+	// considerations over all other factors for delivery, and prevent awakening
+	// the device.
+	ApnsPriorityPower = 1
 )
 
 // Alert is APNs payload
@@ -43,6 +62,22 @@ type Alert struct {
 	TitleLocKey  string   `json:"title-loc-key,omitempty"`
 }
 
+// AndroidNotification is GCM notification payload
+type AndroidNotification struct {
+	Title        string `json:"title,omitempty"`
+	Body         string `json:"body,omitempty"`
+	Icon         string `json:"icon,omitempty"`
+	Sound        string `json:"sound,omitempty"`
+	Badge        string `json:"badge,omitempty"`
+	Tag          string `json:"tag,omitempty"`
+	Color        string `json:"color,omitempty"`
+	ClickAction  string `json:"click_action,omitempty"`
+	BodyLocKey   string `json:"body_loc_key,omitempty"`
+	BodyLocArgs  string `json:"body_loc_args,omitempty"`
+	TitleLocArgs string `json:"title_loc_args,omitempty"`
+	TitleLocKey  string `json:"title_loc_key,omitempty"`
+}
+
 // RequestPush support multiple notification request.
 type RequestPush struct {
 	Notifications []PushNotification `json:"notifications" binding:"required"`
@@ -51,405 +86,698 @@ type RequestPush struct {
 // PushNotification is single notification request
 type PushNotification struct {
 	// Common
-	Tokens           []string `json:"tokens" binding:"required"`
-	Platform         int      `json:"platform" binding:"required"`
-	Message          string   `json:"message" binding:"required"`
-	Title            string   `json:"title,omitempty"`
-	Priority         string   `json:"priority,omitempty"`
-	ContentAvailable bool     `json:"content_available,omitempty"`
-	Sound            string   `json:"sound,omitempty"`
-	Data             D        `json:"data,omitempty"`
+	Tokens           []string          `json:"tokens" binding:"required"`
+	Platform         int               `json:"platform" binding:"required"`
+	Message          string            `json:"message" binding:"required"`
+	Title            string            `json:"title,omitempty"`
+	Priority         Priority          `json:"priority,omitempty"`
+	ContentAvailable bool              `json:"content_available,omitempty"`
+	Sound            string            `json:"sound,omitempty"`
+	Data             D                 `json:"data,omitempty"`
+	Refs             map[string]string `json:"refs,omitempty"`
+	Timezone         string            `json:"timezone,omitempty"`
+	Variants         []Variant         `json:"variants,omitempty"`
+	TestOnly         bool              `json:"test_only,omitempty"`
+
+	// Truncated is true if message or title is trimmed by trim_payload.
+	Truncated bool `json:"-"`
+
+	// Variant is name of A/B testing variant assigned to tokens.
+	Variant string `json:"-"`
+
+	// Tracing, copied from X-Request-ID and traceparent headers.
+	RequestID   string `json:"-"`
+	TraceParent string `json:"-"`
 
 	// Android
-	APIKey                string           `json:"api_key,omitempty"`
-	To                    string           `json:"to,omitempty"`
-	CollapseKey           string           `json:"collapse_key,omitempty"`
-	DelayWhileIdle        bool             `json:"delay_while_idle,omitempty"`
-	TimeToLive            *uint            `json:"time_to_live,omitempty"`
-	RestrictedPackageName string           `json:"restricted_package_name,omitempty"`
-	DryRun                bool             `json:"dry_run,omitempty"`
-	Notification          gcm.Notification `json:"notification,omitempty"`
+	APIKey                string              `json:"api_key,omitempty"`
+	To                    string              `json:"to,omitempty"`
+	CollapseKey           string              `json:"collapse_key,omitempty"`
+	DelayWhileIdle        bool                `json:"delay_while_idle,omitempty"`
+	TimeToLive            *uint               `json:"time_to_live,omitempty"`
+	RestrictedPackageName string              `json:"restricted_package_name,omitempty"`
+	DryRun                bool                `json:"dry_run,omitempty"`
+	Notification          AndroidNotification `json:"notification,omitempty"`
 
 	// iOS
 	Expiration int64    `json:"expiration,omitempty"`
 	ApnsID     string   `json:"apns_id,omitempty"`
 	Topic      string   `json:"topic,omitempty"`
+	PushType   string   `json:"push_type,omitempty"`
 	Badge      int      `json:"badge,omitempty"`
 	Category   string   `json:"category,omitempty"`
 	URLArgs    []string `json:"url-args,omitempty"`
 	Alert      Alert    `json:"alert,omitempty"`
 }
 
+// apnsTopicSuffix is suffix of topic required by push type.
+var apnsTopicSuffix = map[string]string{
+	"alert":        "",
+	"background":   "",
+	"voip":         ".voip",
+	"complication": ".complication",
+	"location":     ".location-query",
+	"liveactivity": ".push-type.liveactivity",
+}
+
+var uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// NewUUID generate random (version 4) UUID.
+func NewUUID() string {
+	var u [16]byte
+	rand.Read(u[:])
+
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
 // CheckMessage for check request message
 func CheckMessage(req PushNotification) error {
-	var msg string
 	if req.Message == "" {
-		msg = "the message must not be empty"
-		LogAccess.Debug(msg)
-		return errors.New(msg)
+		return newMessageError(ErrCodeEmptyMessage, "message", "the message must not be empty")
 	}
 
 	if len(req.Tokens) == 0 {
-		msg = "the message must specify at least one registration ID"
-		LogAccess.Debug(msg)
-		return errors.New(msg)
+		return newMessageError(ErrCodeMissingToken, "tokens", "the message must specify at least one registration ID")
 	}
 
 	if len(req.Tokens) == PlatFormIos && len(req.Tokens[0]) == 0 {
-		msg = "the token must not be empty"
-		LogAccess.Debug(msg)
-		return errors.New(msg)
+		return newMessageError(ErrCodeEmptyToken, "tokens", "the token must not be empty")
+	}
+
+	if req.Platform == PlatFormIos && req.ApnsID != "" && !uuidPattern.MatchString(req.ApnsID) {
+		return newMessageError(ErrCodeInvalidApnsID, "apns_id", "the apns_id must be a canonical UUID")
+	}
+
+	if _, ok := apnsTopicSuffix[req.PushType]; req.Platform == PlatFormIos && req.PushType != "" && !ok {
+		return newMessageError(ErrCodeUnsupportedPush, "push_type", "the push_type is not supported: "+req.PushType)
 	}
 
 	if req.Platform == PlatFormAndroid && len(req.Tokens) > 1000 {
-		msg = "the message may specify at most 1000 registration IDs"
-		LogAccess.Debug(msg)
-		return errors.New(msg)
+		return newMessageError(ErrCodeTooManyTokens, "tokens", "the message may specify at most 1000 registration IDs")
+	}
+
+	if req.Platform == PlatFormAndroid && req.RestrictedPackageName != "" && req.APIKey == "" &&
+		PushConf.Android.EnforcePackage && PushConf.Android.PackageName != "" &&
+		req.RestrictedPackageName != PushConf.Android.PackageName {
+		return newMessageError(ErrCodePackageMismatch, "restricted_package_name",
+			"the restricted_package_name must match package name "+PushConf.Android.PackageName)
 	}
 
 	// ref: https://developers.google.com/cloud-messaging/http-server-ref
 	if req.Platform == PlatFormAndroid && req.TimeToLive != nil && (*req.TimeToLive < uint(0) || uint(2419200) < *req.TimeToLive) {
-		msg = "the message's TimeToLive field must be an integer " +
-			"between 0 and 2419200 (4 weeks)"
-		LogAccess.Debug(msg)
-		return errors.New(msg)
+		return newMessageError(ErrCodeTimeToLiveOutRange, "time_to_live", "the message's TimeToLive field must be an integer "+
+			"between 0 and 2419200 (4 weeks)")
 	}
 
-	return nil
+	if req.Priority.Apns() < 0 {
+		return newMessageError(ErrCodeInvalidPriority, "priority", "the priority must be normal, high, 1, 5 or 10")
+	}
+
+	return CheckSchema(req)
 }
 
-// SetProxy only working for GCM server.
-func SetProxy(proxy string) error {
+// LintMessage check common mistakes of notification.
+// It return warnings only, the notification is still sent.
+func LintMessage(req PushNotification) []string {
+	var warnings []string
+
+	if req.Platform == PlatFormIos && req.ContentAvailable && !req.Priority.IsNormal() {
+		warnings = append(warnings, "background notification with content_available should use normal priority")
+	}
+
+	for k := range req.Data {
+		if len(k) > maxDataKeyLength {
+			warnings = append(warnings, fmt.Sprintf("data key %s is longer than %d characters", k, maxDataKeyLength))
+		}
+
+		if req.Platform == PlatFormAndroid && (k == "from" || strings.HasPrefix(k, "google") || strings.HasPrefix(k, "gcm")) {
+			warnings = append(warnings, fmt.Sprintf("data key %s is reserved by GCM", k))
+		}
+	}
+
+	// emoji outside the basic multilingual plane count as two characters on device.
+	if length := len(utf16.Encode([]rune(req.Message))); length > maxMessageLength {
+		warnings = append(warnings, fmt.Sprintf("message length %d is over %d characters and may be truncated on device", length, maxMessageLength))
+	}
+
+	return warnings
+}
 
+// newProxyTransport create http transport through HTTP or SOCKS5 proxy, selected by URL scheme.
+func newProxyTransport(proxy string) (*http.Transport, error) {
 	proxyURL, err := url.ParseRequestURI(proxy)
 
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL.Scheme != "socks5" {
+		return &http.Transport{Proxy: http.ProxyURL(proxyURL)}, nil
+	}
+
+	var auth *netproxy.Auth
+	if proxyURL.User != nil {
+		auth = &netproxy.Auth{
+			User: proxyURL.User.Username(),
+		}
+		auth.Password, _ = proxyURL.User.Password()
+	}
+
+	dialer, err := netproxy.SOCKS5("tcp", proxyURL.Host, auth, netproxy.Direct)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Transport{Dial: dialer.Dial}, nil
+}
+
+// newGCMProxyTransport create transport of GCM through proxy.
+// Client certificate of android proxy_cert is presented on TLS connections.
+func newGCMProxyTransport(proxy string) (*http.Transport, error) {
+	transport, err := newProxyTransport(proxy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if PushConf.Android.ProxyCert != "" {
+		cert, err := tls.LoadX509KeyPair(PushConf.Android.ProxyCert, PushConf.Android.ProxyKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		}
+	}
+
+	return transport, nil
+}
+
+// SetProxy only working for GCM server.
+// Client certificate of android proxy_cert is presented on TLS connections.
+func SetProxy(proxy string) error {
+	transport, err := newGCMProxyTransport(proxy)
+
 	if err != nil {
 		return err
 	}
 
-	http.DefaultTransport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	http.DefaultTransport = transport
 	LogAccess.Debug("Set http proxy as " + proxy)
 
 	return nil
 }
 
 // CheckPushConf provide check your yml config.
+// All config errors are reported together rather than failing on the first one.
 func CheckPushConf() error {
-	if !PushConf.Ios.Enabled && !PushConf.Android.Enabled {
-		return errors.New("Please enable iOS or Android config in yml config")
+	var errs []string
+
+	if !PushConf.Ios.Enabled && !PushConf.Android.Enabled && !PushConf.Ntfy.Enabled && !PushConf.Gotify.Enabled && !PushConf.Telegram.Enabled &&
+		!PushConf.Slack.Enabled && !PushConf.Teams.Enabled {
+		errs = append(errs, "Please enable iOS or Android config in yml config")
+	}
+
+	if PushConf.Ios.Enabled && !hasAPNs {
+		errs = append(errs, "The iOS platform is not built in, rebuild without noapns tag")
 	}
 
-	if PushConf.Ios.Enabled {
+	if PushConf.Android.Enabled && !hasGCM {
+		errs = append(errs, "The Android platform is not built in, rebuild without nogcm tag")
+	}
+
+	if PushConf.Ios.Enabled && hasAPNs {
 		if PushConf.Ios.KeyPath == "" {
-			return errors.New("Missing iOS certificate path")
+			errs = append(errs, "Missing iOS certificate path")
+		} else if _, err := loadIosCertificate(PushConf.Ios.KeyPath, PushConf.Ios.Password); err != nil {
+			errs = append(errs, "Can't load iOS certificate: "+err.Error())
+		}
+
+		for i, c := range PushConf.Ios.Certs {
+			if c.Topic == "" {
+				errs = append(errs, fmt.Sprintf("Missing topic of iOS certs[%d]", i))
+			}
+
+			if _, err := loadIosCertificate(c.KeyPath, c.Password); err != nil {
+				errs = append(errs, fmt.Sprintf("Can't load iOS certificate of certs[%d]: %s", i, err.Error()))
+			}
 		}
 	}
 
 	if PushConf.Android.Enabled {
 		if PushConf.Android.APIKey == "" {
-			return errors.New("Missing Android API Key")
+			errs = append(errs, "Missing Android API Key")
+		}
+
+		if PushConf.Android.ProxyCert != "" {
+			if _, err := tls.LoadX509KeyPair(PushConf.Android.ProxyCert, PushConf.Android.ProxyKey); err != nil {
+				errs = append(errs, "Can't load Android proxy certificate: "+err.Error())
+			}
 		}
 	}
 
-	return nil
-}
+	if PushConf.Ntfy.Enabled && PushConf.Ntfy.ServerURL == "" {
+		errs = append(errs, "Missing ntfy server url")
+	}
 
-// InitAPNSClient use for initialize APNs Client.
-func InitAPNSClient() error {
-	if PushConf.Ios.Enabled {
-		var err error
-		ext := filepath.Ext(PushConf.Ios.KeyPath)
-
-		switch ext {
-		case ".p12":
-			CertificatePemIos, err = certificate.FromP12File(PushConf.Ios.KeyPath, PushConf.Ios.Password)
-		case ".pem":
-			CertificatePemIos, err = certificate.FromPemFile(PushConf.Ios.KeyPath, PushConf.Ios.Password)
-		default:
-			err = errors.New("Wrong Certificate key extension.")
-		}
+	if PushConf.Gotify.Enabled && PushConf.Gotify.ServerURL == "" {
+		errs = append(errs, "Missing Gotify server url")
+	}
 
-		if err != nil {
-			LogError.Error("Cert Error:", err.Error())
+	if PushConf.Telegram.Enabled && PushConf.Telegram.BotToken == "" {
+		errs = append(errs, "Missing Telegram bot token")
+	}
 
-			return err
+	if PushConf.Ios.Enabled && PushConf.Ios.Encrypt.Enabled {
+		if _, err := newAEAD(PushConf.Ios.Encrypt.Key); err != nil {
+			errs = append(errs, "Wrong iOS encrypt key: "+err.Error())
 		}
+	}
 
-		if PushConf.Ios.Production {
-			ApnsClient = apns.NewClient(CertificatePemIos).Production()
-		} else {
-			ApnsClient = apns.NewClient(CertificatePemIos).Development()
+	if PushConf.Android.Enabled && PushConf.Android.Encrypt.Enabled {
+		if _, err := newAEAD(PushConf.Android.Encrypt.Key); err != nil {
+			errs = append(errs, "Wrong Android encrypt key: "+err.Error())
 		}
 	}
 
-	return nil
-}
-
-// InitWorkers for initialize all workers.
-func InitWorkers(workerNum int64, queueNum int64) {
-	LogAccess.Debug("worker number is ", workerNum, ", queue number is ", queueNum)
-	QueueNotification = make(chan PushNotification, queueNum)
-	for i := int64(0); i < workerNum; i++ {
-		go startWorker()
+	if PushConf.Ios.Enabled && PushConf.Ios.Quiet.Enabled {
+		if err := checkQuiet(PushConf.Ios.Quiet); err != nil {
+			errs = append(errs, "Wrong iOS quiet hours: "+err.Error())
+		}
 	}
-}
 
-func startWorker() {
-	for {
-		notification := <-QueueNotification
-		switch notification.Platform {
-		case PlatFormIos:
-			PushToIOS(notification)
-		case PlatFormAndroid:
-			PushToAndroid(notification)
+	if PushConf.Android.Enabled && PushConf.Android.Quiet.Enabled {
+		if err := checkQuiet(PushConf.Android.Quiet); err != nil {
+			errs = append(errs, "Wrong Android quiet hours: "+err.Error())
 		}
 	}
-}
 
-// queueNotification add notification to queue list.
-func queueNotification(req RequestPush) int {
-	var count int
-	for _, notification := range req.Notifications {
-		switch notification.Platform {
-		case PlatFormIos:
-			if !PushConf.Ios.Enabled {
-				continue
-			}
-		case PlatFormAndroid:
-			if !PushConf.Android.Enabled {
-				continue
-			}
-		}
-		QueueNotification <- notification
+	if PushConf.Core.Chaos.Enabled && PushConf.Core.Mode == "release" {
+		errs = append(errs, "The chaos can't be enabled in release mode")
+	}
 
-		count += len(notification.Tokens)
+	if PushConf.Core.Preflight.Enabled && PushConf.Core.Preflight.Action != "warn" && PushConf.Core.Preflight.Action != "fail" {
+		errs = append(errs, "The preflight action must be warn or fail")
 	}
 
-	StatStorage.AddTotalCount(int64(count))
+	if PushConf.Core.SSL && (PushConf.Core.CertPath == "" || PushConf.Core.KeyPath == "") {
+		errs = append(errs, "Missing SSL certificate or key path")
+	}
 
-	return count
-}
+	if PushConf.Core.WorkerNum <= 0 {
+		errs = append(errs, "The worker_num must be greater than 0")
+	}
 
-func iosAlertDictionary(payload *payload.Payload, req PushNotification) *payload.Payload {
-	// Alert dictionary
+	if PushConf.Core.QueueNum <= 0 {
+		errs = append(errs, "The queue_num must be greater than 0")
+	}
+
+	if PushConf.Core.Rejected.Size < 0 {
+		errs = append(errs, "The rejected size can't be negative")
+	}
 
-	if len(req.Title) > 0 {
-		payload.AlertTitle(req.Title)
+	if PushConf.Core.Rejected.MaxBody < 0 {
+		errs = append(errs, "The rejected max_body can't be negative")
 	}
 
-	if len(req.Alert.TitleLocKey) > 0 {
-		payload.AlertTitleLocKey(req.Alert.TitleLocKey)
+	if PushConf.Ios.Enabled && PushConf.Ios.HTTP.RootCA != "" {
+		if _, err := loadRootCAs(PushConf.Ios.HTTP.RootCA); err != nil {
+			errs = append(errs, "Can't load iOS root CA: "+err.Error())
+		}
 	}
 
-	if len(req.Alert.LocArgs) > 0 {
-		payload.AlertLocArgs(req.Alert.LocArgs)
+	if PushConf.Android.Enabled && PushConf.Android.HTTP.RootCA != "" {
+		if _, err := loadRootCAs(PushConf.Android.HTTP.RootCA); err != nil {
+			errs = append(errs, "Can't load Android root CA: "+err.Error())
+		}
 	}
 
-	if len(req.Alert.TitleLocArgs) > 0 {
-		payload.AlertTitleLocArgs(req.Alert.TitleLocArgs)
+	if !hasQueue(PushConf.Core.QueueEngine) {
+		errs = append(errs, "Unknown queue engine "+PushConf.Core.QueueEngine)
 	}
 
-	if len(req.Alert.Body) > 0 {
-		payload.AlertBody(req.Alert.Body)
+	if _, err := parseCIDRs(PushConf.Core.Access.Allow); err != nil {
+		errs = append(errs, "Wrong access allow list: "+err.Error())
 	}
 
-	if len(req.Alert.LaunchImage) > 0 {
-		payload.AlertLaunchImage(req.Alert.LaunchImage)
+	if _, err := parseCIDRs(PushConf.Core.Access.Deny); err != nil {
+		errs = append(errs, "Wrong access deny list: "+err.Error())
 	}
 
-	if len(req.Alert.LocKey) > 0 {
-		payload.AlertLocKey(req.Alert.LocKey)
+	for _, path := range schemaPaths() {
+		if _, err := loadSchema(path); err != nil {
+			errs = append(errs, "Can't load data schema: "+err.Error())
+		}
 	}
 
-	if len(req.Alert.Action) > 0 {
-		payload.AlertAction(req.Alert.Action)
+	if PushConf.Ios.FallbackProxy != "" {
+		if _, err := newProxyTransport(PushConf.Ios.FallbackProxy); err != nil {
+			errs = append(errs, "Wrong iOS fallback proxy: "+err.Error())
+		}
 	}
 
-	if len(req.Alert.ActionLocKey) > 0 {
-		payload.AlertActionLocKey(req.Alert.ActionLocKey)
+	if PushConf.Android.FallbackProxy != "" {
+		if _, err := newProxyTransport(PushConf.Android.FallbackProxy); err != nil {
+			errs = append(errs, "Wrong Android fallback proxy: "+err.Error())
+		}
 	}
 
-	// General
+	if _, err := newAuth(PushConf); err != nil {
+		errs = append(errs, "Wrong auth config: "+err.Error())
+	}
 
-	if len(req.Category) > 0 {
-		payload.Category(req.Category)
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
 	}
 
-	return payload
+	return nil
 }
 
-// GetIOSNotification use for define iOS notificaiton.
-// The iOS Notification Payload
-// ref: https://developer.apple.com/library/ios/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/Chapters/TheNotificationPayload.html
-func GetIOSNotification(req PushNotification) *apns.Notification {
-	notification := &apns.Notification{
-		ApnsID: req.ApnsID,
-		Topic:  req.Topic,
+// TransformData apply transform rules (strip, rename and inject) on the data field.
+func TransformData(data D, rule config.SectionTransform) D {
+	if len(rule.Strip) == 0 && len(rule.Rename) == 0 && len(rule.Inject) == 0 {
+		return data
 	}
 
-	if req.Expiration > 0 {
-		notification.Expiration = time.Unix(req.Expiration, 0)
+	result := make(D, len(data)+len(rule.Inject))
+	for k, v := range data {
+		result[k] = v
 	}
 
-	if len(req.Priority) > 0 && req.Priority == "normal" {
-		notification.Priority = apns.PriorityLow
+	for _, k := range rule.Strip {
+		delete(result, k)
 	}
 
-	payload := payload.NewPayload().Alert(req.Message)
-
-	if req.Badge > 0 {
-		payload.Badge(req.Badge)
+	for from, to := range rule.Rename {
+		if v, ok := result[from]; ok {
+			delete(result, from)
+			result[to] = v
+		}
 	}
 
-	if len(req.Sound) > 0 {
-		payload.Sound(req.Sound)
+	for k, v := range rule.Inject {
+		result[k] = v
 	}
 
-	if req.ContentAvailable {
-		payload.ContentAvailable()
+	return result
+}
+
+// loadRootCAs return system cert pool with extra PEM certificates of file,
+// or all files of directory.
+func loadRootCAs(path string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+
+	if err != nil {
+		pool = x509.NewCertPool()
 	}
 
-	if len(req.URLArgs) > 0 {
-		payload.URLArgs(req.URLArgs)
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
 	}
 
-	for k, v := range req.Data {
-		payload.Custom(k, v)
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*")); err != nil {
+			return nil, err
+		}
 	}
 
-	payload = iosAlertDictionary(payload, req)
+	var count int
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if pool.AppendCertsFromPEM(data) {
+			count++
+		}
+	}
 
-	notification.Payload = payload
+	if count == 0 {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
 
-	return notification
+	return pool, nil
 }
 
-// PushToIOS provide send notification to APNs server.
-func PushToIOS(req PushNotification) bool {
-	LogAccess.Debug("Start push notification for iOS")
+// tuneTransport apply connection pool, TLS session cache and root CA config to transport.
+func tuneTransport(transport *http.Transport, conf config.SectionHTTP) error {
+	if conf.MaxIdleConns > 0 {
+		transport.MaxIdleConns = conf.MaxIdleConns
+		transport.MaxIdleConnsPerHost = conf.MaxIdleConns
+	}
 
-	var isError bool
+	if conf.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(conf.IdleConnTimeout) * time.Second
+	}
 
-	notification := GetIOSNotification(req)
+	if conf.TLSSessionCache > 0 {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
 
-	for _, token := range req.Tokens {
-		notification.DeviceToken = token
+		transport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(conf.TLSSessionCache)
+	}
 
-		// send ios notification
-		res, err := ApnsClient.Push(notification)
+	// e.g. certificate of egress proxy which re-signs TLS traffic.
+	if conf.RootCA != "" {
+		pool, err := loadRootCAs(conf.RootCA)
 
 		if err != nil {
-			// apns server error
-			LogPush(FailedPush, token, req, err)
-			isError = true
-			StatStorage.AddIosError(1)
-			continue
+			return err
 		}
 
-		if res.StatusCode != 200 {
-			// error message:
-			// ref: https://github.com/sideshow/apns2/blob/master/response.go#L14-L65
-			LogPush(FailedPush, token, req, errors.New(res.Reason))
-			StatStorage.AddIosError(1)
-			continue
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
 		}
 
-		if res.Sent() {
-			LogPush(SucceededPush, token, req, nil)
-			StatStorage.AddIosSuccess(1)
-		}
+		transport.TLSClientConfig.RootCAs = pool
 	}
 
-	return isError
+	return nil
 }
 
-// GetAndroidNotification use for define Android notificaiton.
-// HTTP Connection Server Reference for Android
-// https://developers.google.com/cloud-messaging/http-server-ref
-func GetAndroidNotification(req PushNotification) gcm.HttpMessage {
-	notification := gcm.HttpMessage{
-		To:                    req.To,
-		CollapseKey:           req.CollapseKey,
-		ContentAvailable:      req.ContentAvailable,
-		DelayWhileIdle:        req.DelayWhileIdle,
-		TimeToLive:            req.TimeToLive,
-		RestrictedPackageName: req.RestrictedPackageName,
-		DryRun:                req.DryRun,
-	}
+// switchTransport is default transport used by GCM, failover switch egress of
+// it without replacing http.DefaultTransport while workers are pushing.
+type switchTransport struct {
+	current atomic.Value
+	// primary is transport of startup config, GCM fails back to it.
+	primary *http.Transport
+}
 
-	notification.RegistrationIds = req.Tokens
+// RoundTrip send request by current transport, Retry-After of throttled
+// GCM response is recorded for backoff.
+func (t *switchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.current.Load().(*http.Transport).RoundTrip(req)
 
-	if len(req.Priority) > 0 && req.Priority == "high" {
-		notification.Priority = "high"
+	if err == nil && req.URL.Host == gcmHost {
+		recordRetryAfter(PlatFormAndroid, res)
 	}
 
-	// Add another field
-	if len(req.Data) > 0 {
-		notification.Data = make(map[string]interface{})
-		for k, v := range req.Data {
-			notification.Data[k] = v
-		}
-	}
+	return res, err
+}
 
-	notification.Notification = &req.Notification
+// swap replace current transport and close idle connections of old one,
+// requests in flight still finish with old one.
+func (t *switchTransport) swap(transport *http.Transport) {
+	old, _ := t.current.Load().(*http.Transport)
+	t.current.Store(transport)
 
-	// Set request message if body is empty
-	if len(notification.Notification.Body) == 0 {
-		notification.Notification.Body = req.Message
+	if old != nil && old != transport {
+		old.CloseIdleConnections()
 	}
+}
+
+// gcmHost is host of GCM HTTP endpoint.
+const gcmHost = "gcm-http.googleapis.com"
 
-	if len(req.Title) > 0 {
-		notification.Notification.Title = req.Title
+// gcmTransport is installed as http.DefaultTransport by InitGCMTransport.
+var gcmTransport = &switchTransport{}
+
+// InitGCMTransport apply android http config to default transport used by GCM,
+// and install it as switchable transport for failover. It must be called before workers start.
+func InitGCMTransport() error {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil
 	}
 
-	if len(req.Sound) > 0 {
-		notification.Notification.Sound = req.Sound
+	if err := tuneTransport(transport, PushConf.Android.HTTP); err != nil {
+		return err
 	}
 
-	return notification
+	gcmTransport = &switchTransport{primary: transport}
+	gcmTransport.swap(transport)
+	http.DefaultTransport = gcmTransport
+
+	return nil
 }
 
-// PushToAndroid provide send notification to Android server.
-func PushToAndroid(req PushNotification) bool {
-	LogAccess.Debug("Start push notification for Android")
+// switchGCMTransport switch egress of GCM to transport.
+func switchGCMTransport(transport *http.Transport) error {
+	if http.DefaultTransport != http.RoundTripper(gcmTransport) {
+		return errors.New("GCM transport is not initialized")
+	}
 
-	var APIKey string
+	gcmTransport.swap(transport)
 
-	// check message
-	err := CheckMessage(req)
+	return nil
+}
 
-	if err != nil {
-		LogError.Error("request error: " + err.Error())
-		return false
+// InitWorkers for initialize all workers.
+func InitWorkers(workerNum int64, queueNum int64) {
+	LogAccess.Debug("worker number is ", workerNum, ", queue number is ", queueNum)
+	conf := PushConf
+	conf.Core.QueueNum = queueNum
+	QueueNotification = newQueue(conf)
+	for i := int64(0); i < workerNum; i++ {
+		go startWorker()
 	}
 
-	notification := GetAndroidNotification(req)
-
-	if APIKey = PushConf.Android.APIKey; req.APIKey != "" {
-		APIKey = req.APIKey
+	if _, ok := QueueNotification.(QueueAge); ok && (PushConf.Core.QueueAgeAlert > 0 || PushConf.Stat.Statsd.Enabled) {
+		go monitorQueueAge()
 	}
+}
+
+func startWorker() {
+	for {
+		waitMaintenance()
+		notification, err := QueueNotification.Dequeue()
+
+		if err != nil {
+			LogError.Error("Can't dequeue notification: ", err)
+			continue
+		}
 
-	res, err := gcm.SendHttp(APIKey, notification)
+		atomic.AddInt64(&working, 1)
 
-	if err != nil {
-		// GCM server error
-		LogError.Error("GCM server error: " + err.Error())
+		// keep notification in queue for export in maintenance mode.
+		if IsMaintenance() {
+			QueueNotification.Nack(notification)
+			atomic.AddInt64(&working, -1)
+			continue
+		}
+
+		// keep notification of disabled app until it is enabled again, or drop it.
+		if parkNotification(notification) {
+			QueueNotification.Ack(notification)
+			atomic.AddInt64(&working, -1)
+			continue
+		}
+
+		waitOutage(notification.Platform)
+		switch notification.Platform {
+		case PlatFormIos:
+			PushToIOS(notification)
+		case PlatFormAndroid:
+			PushToAndroid(notification)
+		case PlatFormNtfy:
+			PushToNtfy(notification)
+		case PlatFormGotify:
+			PushToGotify(notification)
+		case PlatFormTelegram:
+			PushToTelegram(notification)
+		case PlatFormSlack:
+			PushToSlack(notification)
+		case PlatFormTeams:
+			PushToTeams(notification)
+		default:
+			LogError.Error(fmt.Sprintf("unknown platform %d of notification, drop %d tokens", notification.Platform, len(notification.Tokens)))
+		}
+
+		if err := QueueNotification.Ack(notification); err != nil {
+			LogError.Error("Can't ack notification: ", err)
+		}
 
-		return false
+		atomic.AddInt64(&working, -1)
 	}
+}
 
-	LogAccess.Debug(fmt.Sprintf("Android Success count: %d, Failure count: %d", res.Success, res.Failure))
-	StatStorage.AddAndroidSuccess(int64(res.Success))
-	StatStorage.AddAndroidError(int64(res.Failure))
+// queueNotification add notification to queue list.
+func queueNotification(req RequestPush) int {
+	var count int
+	var notifications []PushNotification
+	for _, notification := range req.Notifications {
+		notifications = append(notifications, splitVariants(notification)...)
+	}
 
-	for k, result := range res.Results {
-		if result.Error != "" {
-			LogPush(FailedPush, req.Tokens[k], req, errors.New(result.Error))
+	for _, notification := range notifications {
+		switch notification.Platform {
+		case PlatFormIos:
+			if !PushConf.Ios.Enabled {
+				continue
+			}
+		case PlatFormAndroid:
+			if !PushConf.Android.Enabled {
+				continue
+			}
+		case PlatFormNtfy:
+			if !PushConf.Ntfy.Enabled {
+				continue
+			}
+		case PlatFormGotify:
+			if !PushConf.Gotify.Enabled {
+				continue
+			}
+		case PlatFormTelegram:
+			if !PushConf.Telegram.Enabled {
+				continue
+			}
+		case PlatFormSlack:
+			if !PushConf.Slack.Enabled {
+				continue
+			}
+		case PlatFormTeams:
+			if !PushConf.Teams.Enabled {
+				continue
+			}
+		}
+
+		if holdQuiet(notification) {
+			count += len(notification.Tokens)
 			continue
 		}
 
-		LogPush(SucceededPush, req.Tokens[k], req, nil)
+		if notification.Platform == PlatFormAndroid && PushConf.Android.Batch.Enabled {
+			batchNotification(notification)
+			count += len(notification.Tokens)
+			continue
+		}
+
+		if !enqueueNotification(notification) {
+			continue
+		}
+
+		count += len(notification.Tokens)
+	}
+
+	StatStorage.AddTotalCount(int64(count))
+
+	return count
+}
+
+// topicForPushType append suffix of push type to topic (bundle ID),
+// topic already ending with the suffix is kept.
+func topicForPushType(topic, pushType string) string {
+	suffix := apnsTopicSuffix[pushType]
+
+	if topic == "" || suffix == "" || strings.HasSuffix(topic, suffix) {
+		return topic
 	}
 
-	return true
+	return topic + suffix
 }
@@ -0,0 +1,57 @@
+package gorush
+
+import (
+	"strings"
+)
+
+// NtfyMessage is JSON message of ntfy server.
+// ref: https://docs.ntfy.sh/publish/#publish-as-json
+type NtfyMessage struct {
+	Topic    string `json:"topic"`
+	Message  string `json:"message"`
+	Title    string `json:"title,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// GetNtfyMessage use for define ntfy message of topic.
+func GetNtfyMessage(req PushNotification, topic string) NtfyMessage {
+	message := NtfyMessage{
+		Topic:   topic,
+		Message: req.Message,
+		Title:   req.Title,
+	}
+
+	if req.Priority.IsHigh() {
+		message.Priority = 4
+	}
+
+	return message
+}
+
+// PushToNtfy provide send notification to ntfy server, tokens are topics.
+func PushToNtfy(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for ntfy")
+
+	var isError bool
+
+	header := map[string]string{}
+	if PushConf.Ntfy.Token != "" {
+		header["Authorization"] = "Bearer " + PushConf.Ntfy.Token
+	}
+
+	for _, topic := range req.Tokens {
+		err := postJSON(strings.TrimSuffix(PushConf.Ntfy.ServerURL, "/"), header, GetNtfyMessage(req, topic))
+
+		if err != nil {
+			LogPush(FailedPush, topic, req, err)
+			StatStorage.AddProviderError("ntfy", 1)
+			isError = true
+			continue
+		}
+
+		LogPush(SucceededPush, topic, req, nil)
+		StatStorage.AddProviderSuccess("ntfy", 1)
+	}
+
+	return isError
+}
@@ -0,0 +1,77 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfyMessage(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"alerts"},
+		Platform: PlatFormNtfy,
+		Message:  "Welcome",
+		Title:    "Gorush",
+		Priority: "high",
+	}
+
+	message := GetNtfyMessage(req, "alerts")
+
+	assert.Equal(t, "alerts", message.Topic)
+	assert.Equal(t, "Welcome", message.Message)
+	assert.Equal(t, "Gorush", message.Title)
+	assert.Equal(t, 4, message.Priority)
+}
+
+func TestPushToNtfy(t *testing.T) {
+	var topics []string
+	var auth string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message NtfyMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		auth = r.Header.Get("Authorization")
+
+		if message.Topic == "forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		topics = append(topics, message.Topic)
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Ntfy.Enabled = true
+	InitAppStatus()
+	PushConf.Ntfy.ServerURL = ts.URL
+	PushConf.Ntfy.Token = "tk_xxxxx"
+
+	req := PushNotification{
+		Tokens:   []string{"alerts", "deploy"},
+		Platform: PlatFormNtfy,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, PushToNtfy(req))
+	assert.Equal(t, []string{"alerts", "deploy"}, topics)
+	assert.Equal(t, "Bearer tk_xxxxx", auth)
+
+	req.Tokens = []string{"forbidden"}
+	assert.True(t, PushToNtfy(req))
+}
+
+func TestMissingNtfyServerURL(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ntfy.Enabled = true
+	PushConf.Ntfy.ServerURL = ""
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Missing ntfy server url", err.Error())
+}
@@ -0,0 +1,109 @@
+package gorush
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// outageWait is interval of paused worker to check the outage state.
+var outageWait = 100 * time.Millisecond
+
+// outageState track consecutive provider failures of platform.
+type outageState struct {
+	sync.Mutex
+	failures int
+	paused   bool
+	probing  bool
+	probeAt  time.Time
+}
+
+var outages = map[int]*outageState{
+	PlatFormIos:     {},
+	PlatFormAndroid: {},
+}
+
+// isOutage return true if push of platform is paused.
+func isOutage(platform int) bool {
+	state, ok := outages[platform]
+	if !ok {
+		return false
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	return state.paused
+}
+
+// waitOutage block worker while push of platform is paused.
+// One worker is released as probe after every probe interval.
+func waitOutage(platform int) {
+	state, ok := outages[platform]
+	if !ok || !PushConf.Core.Outage.Enabled {
+		return
+	}
+
+	for {
+		state.Lock()
+		if !state.paused {
+			state.Unlock()
+			return
+		}
+
+		if !state.probing && !time.Now().Before(state.probeAt) {
+			state.probing = true
+			state.Unlock()
+			LogAccess.Info(fmt.Sprintf("send probe push of %s", typeForPlatForm(platform)))
+			return
+		}
+		state.Unlock()
+
+		time.Sleep(outageWait)
+	}
+}
+
+// reportOutage record push result of platform, failed is true if all tokens
+// are failed by provider error (connection, auth or server error).
+// Platform with fallback_proxy fails over to it once instead of pausing.
+func reportOutage(platform int, failed bool) {
+	state, ok := outages[platform]
+	if !ok || !PushConf.Core.Outage.Enabled {
+		return
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	if !failed {
+		if state.paused {
+			LogAccess.Info(fmt.Sprintf("%s provider is back, resume push", typeForPlatForm(platform)))
+		}
+
+		state.failures = 0
+		state.paused = false
+		state.probing = false
+		return
+	}
+
+	state.failures++
+	probeAt := time.Now().Add(time.Duration(PushConf.Core.Outage.ProbeInterval) * time.Second)
+
+	if state.paused {
+		state.probing = false
+		state.probeAt = probeAt
+		return
+	}
+
+	if state.failures >= PushConf.Core.Outage.Threshold {
+		// try fallback egress before pausing push.
+		if failover(platform) {
+			state.failures = 0
+			return
+		}
+
+		state.paused = true
+		state.probeAt = probeAt
+		LogError.Error(fmt.Sprintf("%s provider outage detected after %d consecutive failures, pause push", typeForPlatForm(platform), state.failures))
+	}
+}
@@ -0,0 +1,51 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOutageDisabled(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Outage.Threshold = 1
+
+	reportOutage(PlatFormIos, true)
+	assert.False(t, isOutage(PlatFormIos))
+}
+
+func TestOutagePauseAndResume(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Outage.Enabled = true
+	PushConf.Core.Outage.Threshold = 2
+	PushConf.Core.Outage.ProbeInterval = 0
+	outageWait = time.Millisecond
+
+	reportOutage(PlatFormAndroid, true)
+	assert.False(t, isOutage(PlatFormAndroid))
+
+	// success reset the failure count
+	reportOutage(PlatFormAndroid, false)
+	reportOutage(PlatFormAndroid, true)
+	assert.False(t, isOutage(PlatFormAndroid))
+
+	reportOutage(PlatFormAndroid, true)
+	assert.True(t, isOutage(PlatFormAndroid))
+	assert.False(t, isOutage(PlatFormIos))
+
+	// first worker is released as probe
+	waitOutage(PlatFormAndroid)
+
+	// failed probe keep the platform paused
+	reportOutage(PlatFormAndroid, true)
+	assert.True(t, isOutage(PlatFormAndroid))
+
+	waitOutage(PlatFormAndroid)
+	reportOutage(PlatFormAndroid, false)
+	assert.False(t, isOutage(PlatFormAndroid))
+
+	// not paused, return immediately
+	waitOutage(PlatFormAndroid)
+	waitOutage(100)
+}
@@ -0,0 +1,47 @@
+package gorush
+
+import (
+	"errors"
+	"strings"
+)
+
+// preflightToken is invalid device token used to probe provider.
+const preflightToken = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Preflight verify credentials of enabled APNs and GCM apps. Failures are logged
+// as warnings, and returned when preflight action is fail.
+func Preflight() error {
+	var errs []string
+
+	if !PushConf.Core.Preflight.Enabled {
+		return nil
+	}
+
+	if PushConf.Ios.Enabled {
+		if err := preflightIOS(""); err != nil {
+			errs = append(errs, "APNs preflight failed: "+err.Error())
+		}
+
+		for _, c := range PushConf.Ios.Certs {
+			if err := preflightIOS(c.Topic); err != nil {
+				errs = append(errs, "APNs preflight of topic "+c.Topic+" failed: "+err.Error())
+			}
+		}
+	}
+
+	if PushConf.Android.Enabled {
+		if err := preflightAndroid(PushConf.Android.APIKey); err != nil {
+			errs = append(errs, "GCM preflight failed: "+err.Error())
+		}
+	}
+
+	for _, msg := range errs {
+		LogError.Warn(msg)
+	}
+
+	if len(errs) > 0 && PushConf.Core.Preflight.Action == "fail" {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPreflightDisabled(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Ios.Enabled = true
+
+	assert.NoError(t, Preflight())
+}
+
+func TestPreflightConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ntfy.Enabled = true
+	PushConf.Core.Preflight.Enabled = true
+	PushConf.Core.Preflight.Action = "abort"
+
+	err := CheckPushConf()
+	assert.Error(t, err)
+	assert.Equal(t, "The preflight action must be warn or fail", err.Error())
+}
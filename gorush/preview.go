@@ -0,0 +1,51 @@
+package gorush
+
+import (
+	"errors"
+	"github.com/appleboy/gorush/internal/json"
+)
+
+// Preview is provider request of notification without sending.
+type Preview struct {
+	Platform string            `json:"platform"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Payload  interface{}       `json:"payload"`
+	// Truncated is true if message or title is trimmed by trim_payload.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// GetPreview return exact APNs payload or GCM message body of notification.
+func GetPreview(req PushNotification) (*Preview, error) {
+	if err := CheckMessage(req); err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{
+		Platform:  typeForPlatForm(req.Platform),
+		Truncated: TrimPayload(&req),
+	}
+
+	switch req.Platform {
+	case PlatFormIos:
+		headers, payload, err := iosPreview(req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		preview.Headers = headers
+		preview.Payload = json.RawMessage(payload)
+	case PlatFormAndroid:
+		payload, err := androidPreview(req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		preview.Payload = payload
+	default:
+		return nil, errors.New("preview only support iOS and Android platform")
+	}
+
+	return preview, nil
+}
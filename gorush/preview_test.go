@@ -0,0 +1,27 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetIOSPreview(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormIos,
+		Message:  "Welcome",
+		Topic:    "com.example.app",
+		Priority: "normal",
+	}
+
+	preview, err := GetPreview(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "ios", preview.Platform)
+	assert.Equal(t, "com.example.app", preview.Headers["apns-topic"])
+	assert.Equal(t, "5", preview.Headers["apns-priority"])
+	assert.Contains(t, string(preview.Payload.(json.RawMessage)), `"alert":"Welcome"`)
+}
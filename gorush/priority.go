@@ -0,0 +1,70 @@
+package gorush
+
+import (
+	"bytes"
+	"github.com/appleboy/gorush/internal/json"
+	"strconv"
+)
+
+// Priority is priority of notification, "normal", "high" or numeric APNs priority (1, 5 or 10).
+type Priority string
+
+// UnmarshalJSON accept both string and number of priority, null is unset.
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		*p = Priority(s)
+		return nil
+	}
+
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+
+	*p = Priority(strconv.Itoa(n))
+	return nil
+}
+
+// Apns return APNs priority, 0 means not set and -1 means invalid priority.
+func (p Priority) Apns() int {
+	switch p {
+	case "":
+		return 0
+	case "normal":
+		return ApnsPriorityLow
+	case "high":
+		return ApnsPriorityHigh
+	}
+
+	n, err := strconv.Atoi(string(p))
+	if err != nil {
+		return -1
+	}
+
+	switch n {
+	case ApnsPriorityPower, ApnsPriorityLow, ApnsPriorityHigh:
+		return n
+	}
+
+	return -1
+}
+
+// IsHigh report whether notification should be delivered immediately.
+func (p Priority) IsHigh() bool {
+	return p.Apns() == ApnsPriorityHigh
+}
+
+// IsNormal report whether notification may be delayed to save power.
+func (p Priority) IsNormal() bool {
+	n := p.Apns()
+	return n == ApnsPriorityLow || n == ApnsPriorityPower
+}
@@ -0,0 +1,39 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPriorityUnmarshalJSON(t *testing.T) {
+	var req PushNotification
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"priority":"high"}`), &req))
+	assert.Equal(t, Priority("high"), req.Priority)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"priority":1}`), &req))
+	assert.Equal(t, Priority("1"), req.Priority)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"priority":true}`), &req))
+
+	// null is unset
+	req = PushNotification{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"priority":null}`), &req))
+	assert.Equal(t, Priority(""), req.Priority)
+	assert.Equal(t, 0, req.Priority.Apns())
+}
+
+func TestPriorityApns(t *testing.T) {
+	assert.Equal(t, 0, Priority("").Apns())
+	assert.Equal(t, ApnsPriorityLow, Priority("normal").Apns())
+	assert.Equal(t, ApnsPriorityHigh, Priority("high").Apns())
+	assert.Equal(t, ApnsPriorityPower, Priority("1").Apns())
+	assert.Equal(t, ApnsPriorityHigh, Priority("10").Apns())
+	assert.Equal(t, -1, Priority("7").Apns())
+	assert.Equal(t, -1, Priority("urgent").Apns())
+
+	assert.True(t, Priority("10").IsHigh())
+	assert.True(t, Priority("1").IsNormal())
+	assert.False(t, Priority("high").IsNormal())
+}
@@ -0,0 +1,65 @@
+package gorush
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"net/http"
+	"time"
+)
+
+// providerClient is HTTP client of webhook providers and callback, slow server
+// must not block workers.
+var providerClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// webhookURL return webhook URL of token, token can be ID or URL of webhooks config.
+// URL which is not in config is rejected, so client can't post to any host.
+func webhookURL(webhooks map[string]string, token string) (string, error) {
+	if url, ok := webhooks[token]; ok {
+		return url, nil
+	}
+
+	for _, url := range webhooks {
+		if url == token {
+			return url, nil
+		}
+	}
+
+	return "", fmt.Errorf("webhook %s is not found", token)
+}
+
+// postJSON post JSON body with extra headers to provider server.
+func postJSON(url string, header map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	res, err := providerClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("server response status code %d", res.StatusCode)
+	}
+
+	return nil
+}
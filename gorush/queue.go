@@ -0,0 +1,223 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Queue interface of notification queue.
+type Queue interface {
+	// Enqueue add notification to queue.
+	Enqueue(PushNotification) error
+	// Dequeue block until notification is available.
+	Dequeue() (PushNotification, error)
+	// Ack mark notification as processed.
+	Ack(PushNotification) error
+	// Nack put notification back to queue for retry.
+	Nack(PushNotification) error
+	// Len return number of notifications waiting in queue.
+	Len() int
+}
+
+// QueueAge is optional interface of queue to report age of
+// the oldest waiting notification of each lane.
+type QueueAge interface {
+	OldestAge() map[string]time.Duration
+}
+
+// QueueTryDequeue is optional interface of queue to dequeue without blocking,
+// export uses it to drain queue while workers are blocked in Dequeue.
+type QueueTryDequeue interface {
+	// TryDequeue return false if no notification is available now.
+	TryDequeue() (PushNotification, bool, error)
+}
+
+// QueueFactory create queue from config.
+type QueueFactory func(config.ConfYaml) Queue
+
+var (
+	queuesLock sync.RWMutex
+	queues     = map[string]QueueFactory{
+		"channel": func(conf config.ConfYaml) Queue {
+			return newChannelQueue(conf.Core.QueueNum, conf.Core.FastLaneTokens)
+		},
+	}
+)
+
+// RegisterQueue add custom queue which can be selected by name
+// in queue_engine config. Register the same name again will replace it.
+func RegisterQueue(name string, factory QueueFactory) {
+	queuesLock.Lock()
+	defer queuesLock.Unlock()
+
+	queues[name] = factory
+}
+
+// hasQueue return true if queue engine is registered.
+func hasQueue(name string) bool {
+	queuesLock.RLock()
+	defer queuesLock.RUnlock()
+
+	_, ok := queues[name]
+
+	return ok
+}
+
+// newQueue create queue of queue engine, default as channel.
+func newQueue(conf config.ConfYaml) Queue {
+	queuesLock.RLock()
+	factory, ok := queues[conf.Core.QueueEngine]
+	queuesLock.RUnlock()
+
+	if !ok {
+		return newChannelQueue(conf.Core.QueueNum, conf.Core.FastLaneTokens)
+	}
+
+	return factory(conf)
+}
+
+// enqueueNotification add notification to queue, return false if failed.
+func enqueueNotification(req PushNotification) bool {
+	if err := QueueNotification.Enqueue(req); err != nil {
+		LogError.Error("Can't enqueue notification: ", err)
+
+		return false
+	}
+
+	publishEvent(EventQueued, "", req, len(req.Tokens), nil)
+
+	return true
+}
+
+// channelQueue is in-memory queue of buffered channel.
+// Notifications with at most fastTokens tokens go to fast lane and
+// are dequeued ahead of large requests.
+type channelQueue struct {
+	// number of notifications put back by Nack and not yet in channel.
+	nacks int64
+
+	notifications chan PushNotification
+	fast          chan PushNotification
+	fastTokens    int
+
+	// enqueue time of waiting notifications of each lane, in queue order.
+	sync.Mutex
+	times map[string][]time.Time
+}
+
+func newChannelQueue(size int64, fastTokens int) *channelQueue {
+	q := &channelQueue{
+		notifications: make(chan PushNotification, size),
+		fastTokens:    fastTokens,
+		times:         map[string][]time.Time{},
+	}
+
+	if fastTokens > 0 {
+		q.fast = make(chan PushNotification, size)
+	}
+
+	return q
+}
+
+func (q *channelQueue) Enqueue(req PushNotification) error {
+	if q.fast != nil && len(req.Tokens) <= q.fastTokens {
+		q.push("fast")
+		q.fast <- req
+
+		return nil
+	}
+
+	q.push("normal")
+	q.notifications <- req
+
+	return nil
+}
+
+func (q *channelQueue) Dequeue() (PushNotification, error) {
+	select {
+	case req := <-q.fast:
+		q.pop("fast")
+		return req, nil
+	default:
+	}
+
+	select {
+	case req := <-q.fast:
+		q.pop("fast")
+		return req, nil
+	case req := <-q.notifications:
+		q.pop("normal")
+		return req, nil
+	}
+}
+
+func (q *channelQueue) TryDequeue() (PushNotification, bool, error) {
+	select {
+	case req := <-q.fast:
+		q.pop("fast")
+		return req, true, nil
+	default:
+	}
+
+	select {
+	case req := <-q.notifications:
+		q.pop("normal")
+		return req, true, nil
+	default:
+	}
+
+	return PushNotification{}, false, nil
+}
+
+// push record enqueue time before notification is sent to channel of lane.
+func (q *channelQueue) push(lane string) {
+	q.Lock()
+	q.times[lane] = append(q.times[lane], time.Now())
+	q.Unlock()
+}
+
+func (q *channelQueue) pop(lane string) {
+	q.Lock()
+	if len(q.times[lane]) > 0 {
+		q.times[lane] = q.times[lane][1:]
+	}
+	q.Unlock()
+}
+
+// OldestAge return age of the oldest waiting notification of normal and fast lane.
+func (q *channelQueue) OldestAge() map[string]time.Duration {
+	q.Lock()
+	defer q.Unlock()
+
+	now := time.Now()
+	ages := map[string]time.Duration{}
+	for lane, times := range q.times {
+		if len(times) > 0 {
+			ages[lane] = now.Sub(times[0])
+		}
+	}
+
+	return ages
+}
+
+func (q *channelQueue) Ack(req PushNotification) error {
+	return nil
+}
+
+// Nack put notification back without blocking the worker.
+func (q *channelQueue) Nack(req PushNotification) error {
+	atomic.AddInt64(&q.nacks, 1)
+	go func() {
+		defer atomic.AddInt64(&q.nacks, -1)
+		q.Enqueue(req)
+	}()
+
+	return nil
+}
+
+// Len include notifications being put back by Nack.
+func (q *channelQueue) Len() int {
+	return len(q.notifications) + len(q.fast) + int(atomic.LoadInt64(&q.nacks))
+}
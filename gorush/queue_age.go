@@ -0,0 +1,61 @@
+package gorush
+
+import (
+	"fmt"
+	"time"
+)
+
+// queueAgeInterval is interval of monitor to check age of queue.
+var queueAgeInterval = time.Second
+
+// queueAges return age of the oldest waiting notification of each lane in
+// milliseconds, it is empty if queue engine doesn't support it.
+func queueAges() map[string]int64 {
+	queue, ok := QueueNotification.(QueueAge)
+	if !ok {
+		return nil
+	}
+
+	ages := map[string]int64{}
+	for lane, age := range queue.OldestAge() {
+		ages[lane] = int64(age / time.Millisecond)
+	}
+
+	return ages
+}
+
+// checkQueueAge log alert once when age of lane is over queue_age_alert seconds,
+// and log again when it is back. alerted is state of lanes between checks.
+func checkQueueAge(ages map[string]int64, alerted map[string]bool) {
+	limit := int64(PushConf.Core.QueueAgeAlert) * 1000
+
+	for _, lane := range []string{"normal", "fast"} {
+		age := ages[lane]
+		if age > limit && !alerted[lane] {
+			alerted[lane] = true
+			LogError.Error(fmt.Sprintf("oldest notification of %s lane is waiting %dms, over limit(%ds), workers can't keep up", lane, age, PushConf.Core.QueueAgeAlert))
+		} else if age <= limit && alerted[lane] {
+			alerted[lane] = false
+			LogAccess.Info(fmt.Sprintf("oldest notification of %s lane is waiting %dms, back under limit(%ds)", lane, age, PushConf.Core.QueueAgeAlert))
+		}
+	}
+}
+
+// monitorQueueAge send age of queue to StatsD and alert on queue_age_alert.
+func monitorQueueAge() {
+	alerted := map[string]bool{}
+
+	for range time.Tick(queueAgeInterval) {
+		ages := queueAges()
+
+		if statsd, ok := StatStorage.(*StatsdStorage); ok {
+			for lane, age := range ages {
+				statsd.gauge("queue.age."+lane, age)
+			}
+		}
+
+		if PushConf.Core.QueueAgeAlert > 0 {
+			checkQueueAge(ages, alerted)
+		}
+	}
+}
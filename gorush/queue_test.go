@@ -0,0 +1,132 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testQueue struct {
+	*channelQueue
+}
+
+func TestChannelQueue(t *testing.T) {
+	q := newChannelQueue(2, 0)
+
+	assert.NoError(t, q.Enqueue(PushNotification{Message: "Welcome"}))
+	assert.Equal(t, 1, q.Len())
+
+	notification, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome", notification.Message)
+	assert.Equal(t, 0, q.Len())
+
+	assert.NoError(t, q.Nack(notification))
+	notification, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome", notification.Message)
+}
+
+func TestChannelQueueTryDequeue(t *testing.T) {
+	q := newChannelQueue(2, 1)
+
+	_, ok, err := q.TryDequeue()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	q.Enqueue(PushNotification{Tokens: []string{"aaaaa", "bbbbb"}, Message: "Hello"})
+	q.Enqueue(PushNotification{Tokens: []string{"ccccc"}, Message: "Welcome"})
+
+	// fast lane first
+	notification, ok, err := q.TryDequeue()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Welcome", notification.Message)
+
+	notification, ok, _ = q.TryDequeue()
+	assert.True(t, ok)
+	assert.Equal(t, "Hello", notification.Message)
+	assert.Empty(t, q.OldestAge())
+}
+
+func TestChannelQueueFastLane(t *testing.T) {
+	q := newChannelQueue(2, 1)
+
+	assert.NoError(t, q.Enqueue(PushNotification{Tokens: []string{"aaaaa", "bbbbb"}, Message: "Campaign"}))
+	assert.NoError(t, q.Enqueue(PushNotification{Tokens: []string{"aaaaa"}, Message: "Welcome"}))
+	assert.Equal(t, 2, q.Len())
+
+	notification, _ := q.Dequeue()
+	assert.Equal(t, "Welcome", notification.Message)
+
+	notification, _ = q.Dequeue()
+	assert.Equal(t, "Campaign", notification.Message)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestRegisterQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	q := &testQueue{channelQueue: newChannelQueue(1, 0)}
+	RegisterQueue("test", func(config.ConfYaml) Queue {
+		return q
+	})
+
+	PushConf.Core.QueueEngine = "test"
+	assert.True(t, hasQueue("test"))
+	assert.True(t, q == newQueue(PushConf))
+
+	// unknown engine
+	PushConf.Core.QueueEngine = "unknown"
+	assert.False(t, hasQueue("unknown"))
+	_, ok := newQueue(PushConf).(*channelQueue)
+	assert.True(t, ok)
+}
+
+func TestUnknownQueueConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Ntfy.Enabled = true
+	PushConf.Core.QueueEngine = "unknown"
+
+	err := CheckPushConf()
+	assert.Error(t, err)
+	assert.Equal(t, "Unknown queue engine unknown", err.Error())
+}
+
+func TestChannelQueueOldestAge(t *testing.T) {
+	q := newChannelQueue(10, 1)
+
+	assert.Empty(t, q.OldestAge())
+
+	q.Enqueue(PushNotification{Tokens: []string{"aaaaa", "bbbbb"}})
+	time.Sleep(10 * time.Millisecond)
+	q.Enqueue(PushNotification{Tokens: []string{"ccccc"}})
+
+	ages := q.OldestAge()
+	assert.True(t, ages["normal"] >= 10*time.Millisecond)
+	assert.True(t, ages["fast"] < ages["normal"])
+
+	q.Dequeue()
+	q.Dequeue()
+	assert.Empty(t, q.OldestAge())
+}
+
+func TestCheckQueueAge(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.QueueAgeAlert = 1
+
+	alerted := map[string]bool{}
+
+	checkQueueAge(map[string]int64{"normal": 500}, alerted)
+	assert.False(t, alerted["normal"])
+
+	checkQueueAge(map[string]int64{"normal": 1500, "fast": 10}, alerted)
+	assert.True(t, alerted["normal"])
+	assert.False(t, alerted["fast"])
+
+	// lane is empty
+	checkQueueAge(map[string]int64{}, alerted)
+	assert.False(t, alerted["normal"])
+}
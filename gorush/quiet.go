@@ -0,0 +1,154 @@
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/config"
+	"sync"
+	"time"
+)
+
+var (
+	quietLock sync.Mutex
+	// quietHeld is notifications held until the end of quiet hours by timer.
+	quietHeld = map[*time.Timer]PushNotification{}
+)
+
+// quietForPlatForm return quiet hours config of platform.
+func quietForPlatForm(platform int) config.SectionQuiet {
+	switch platform {
+	case PlatFormIos:
+		return PushConf.Ios.Quiet
+	case PlatFormAndroid:
+		return PushConf.Android.Quiet
+	default:
+		return config.SectionQuiet{}
+	}
+}
+
+// checkQuiet validate quiet hours config.
+func checkQuiet(rule config.SectionQuiet) error {
+	if _, err := time.Parse("15:04", rule.Start); err != nil {
+		return fmt.Errorf("wrong start time %s of quiet hours", rule.Start)
+	}
+
+	if _, err := time.Parse("15:04", rule.End); err != nil {
+		return fmt.Errorf("wrong end time %s of quiet hours", rule.End)
+	}
+
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return fmt.Errorf("wrong timezone %s of quiet hours", rule.Timezone)
+	}
+
+	if rule.Action != "hold" && rule.Action != "drop" {
+		return errors.New("the action of quiet hours must be hold or drop")
+	}
+
+	return nil
+}
+
+// quietDelay return duration until the end of quiet hours, zero if now is not in quiet hours.
+func quietDelay(rule config.SectionQuiet, timezone string, now time.Time) (time.Duration, error) {
+	if timezone == "" {
+		timezone = rule.Timezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	start, err := time.Parse("15:04", rule.Start)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := time.Parse("15:04", rule.End)
+	if err != nil {
+		return 0, err
+	}
+
+	now = now.In(loc)
+	minute := now.Hour()*60 + now.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	if startMinute <= endMinute {
+		quiet = minute >= startMinute && minute < endMinute
+	} else {
+		// quiet hours cross midnight
+		quiet = minute >= startMinute || minute < endMinute
+	}
+
+	if !quiet {
+		return 0, nil
+	}
+
+	until := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return until.Sub(now), nil
+}
+
+// holdQuiet hold or drop non high priority notification in quiet hours.
+// It return true if the notification is not queued now.
+func holdQuiet(req PushNotification) bool {
+	rule := quietForPlatForm(req.Platform)
+
+	if !rule.Enabled || req.Priority.IsHigh() {
+		return false
+	}
+
+	delay, err := quietDelay(rule, req.Timezone, time.Now())
+
+	if err != nil {
+		LogError.Error("quiet hours error: " + err.Error())
+		return false
+	}
+
+	if delay == 0 {
+		return false
+	}
+
+	if rule.Action == "drop" {
+		LogAccess.Info(fmt.Sprintf("drop %s notification of %d tokens in quiet hours", typeForPlatForm(req.Platform), len(req.Tokens)))
+		return true
+	}
+
+	LogAccess.Info(fmt.Sprintf("hold %s notification of %d tokens for %s in quiet hours", typeForPlatForm(req.Platform), len(req.Tokens), delay))
+	quietLock.Lock()
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		quietLock.Lock()
+		_, ok := quietHeld[timer]
+		delete(quietHeld, timer)
+		quietLock.Unlock()
+
+		if ok {
+			enqueueNotification(req)
+		}
+	})
+	quietHeld[timer] = req
+	quietLock.Unlock()
+
+	return true
+}
+
+// releaseQuiet stop timers of notifications held in quiet hours and return them,
+// e.g. to export them before server exits.
+func releaseQuiet() []PushNotification {
+	quietLock.Lock()
+	defer quietLock.Unlock()
+
+	var notifications []PushNotification
+	for timer, req := range quietHeld {
+		timer.Stop()
+		notifications = append(notifications, req)
+	}
+	quietHeld = map[*time.Timer]PushNotification{}
+
+	return notifications
+}
@@ -0,0 +1,105 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCheckQuiet(t *testing.T) {
+	rule := config.BuildDefaultPushConf().Ios.Quiet
+	assert.NoError(t, checkQuiet(rule))
+
+	rule.Start = "25:00"
+	assert.Equal(t, "wrong start time 25:00 of quiet hours", checkQuiet(rule).Error())
+
+	rule.Start = "22:00"
+	rule.Timezone = "Mars/Olympus"
+	assert.Equal(t, "wrong timezone Mars/Olympus of quiet hours", checkQuiet(rule).Error())
+
+	rule.Timezone = "UTC"
+	rule.Action = "delay"
+	assert.Equal(t, "the action of quiet hours must be hold or drop", checkQuiet(rule).Error())
+}
+
+func TestQuietDelay(t *testing.T) {
+	rule := config.BuildDefaultPushConf().Ios.Quiet
+
+	// not in quiet hours
+	delay, err := quietDelay(rule, "", time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+
+	// before midnight
+	delay, err = quietDelay(rule, "", time.Date(2016, 5, 1, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 8*time.Hour, delay)
+
+	// after midnight
+	delay, err = quietDelay(rule, "", time.Date(2016, 5, 1, 6, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, delay)
+
+	// quiet hours in the same day
+	rule.Start = "01:00"
+	rule.End = "05:00"
+	delay, err = quietDelay(rule, "", time.Date(2016, 5, 1, 6, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+
+	// device timezone (UTC+8)
+	delay, err = quietDelay(rule, "Asia/Taipei", time.Date(2016, 5, 1, 19, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, delay)
+
+	_, err = quietDelay(rule, "Mars/Olympus", time.Now())
+	assert.Error(t, err)
+}
+
+func TestHoldQuiet(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Tokens:   []string{"aaa"},
+		Platform: PlatFormIos,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, holdQuiet(req))
+
+	// quiet all day
+	PushConf.Ios.Quiet.Enabled = true
+	PushConf.Ios.Quiet.Start = "00:00"
+	PushConf.Ios.Quiet.End = "23:59"
+	PushConf.Ios.Quiet.Action = "drop"
+
+	if delay, _ := quietDelay(PushConf.Ios.Quiet, "", time.Now()); delay > 0 {
+		assert.True(t, holdQuiet(req))
+	}
+
+	req.Priority = "high"
+	assert.False(t, holdQuiet(req))
+}
+
+func TestReleaseQuiet(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Ios.Quiet.Enabled = true
+	PushConf.Ios.Quiet.Start = "00:00"
+	PushConf.Ios.Quiet.End = "23:59"
+	PushConf.Ios.Quiet.Action = "hold"
+
+	req := PushNotification{
+		Tokens:   []string{"aaa"},
+		Platform: PlatFormIos,
+		Message:  "Welcome",
+	}
+
+	if delay, _ := quietDelay(PushConf.Ios.Quiet, "", time.Now()); delay == 0 {
+		t.Skip("not in quiet hours")
+	}
+
+	assert.True(t, holdQuiet(req))
+	assert.Equal(t, []PushNotification{req}, releaseQuiet())
+	assert.Empty(t, releaseQuiet())
+}
@@ -0,0 +1,194 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rejectedBodyKey is key of captured request body in gin context.
+const rejectedBodyKey = "rejected_body"
+
+// secretPattern match device tokens and API keys in request body which is not valid JSON.
+var secretPattern = regexp.MustCompile("[0-9A-Za-z_:-]{32,}")
+
+// secretFields is JSON fields of request body masked in rejected samples, tokens
+// are also topics, chat IDs and webhook URLs of providers.
+var secretFields = map[string]bool{
+	"tokens":  true,
+	"to":      true,
+	"api_key": true,
+}
+
+// secretKeyFields is JSON fields of request body whose keys are masked in rejected
+// samples, keys of refs are device tokens.
+var secretKeyFields = map[string]bool{
+	"refs": true,
+}
+
+// RejectedRequest is redacted sample of push request rejected by validation.
+type RejectedRequest struct {
+	Time      int64  `json:"time"`
+	RequestID string `json:"request_id,omitempty"`
+	Path      string `json:"path"`
+	Code      int    `json:"code"`
+	Reason    string `json:"reason"`
+	Body      string `json:"body"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+var (
+	rejectedLock sync.Mutex
+	// rejected keep the last rejected requests, the oldest first.
+	rejected []RejectedRequest
+)
+
+// bodyBuffer keep the first max bytes written.
+type bodyBuffer struct {
+	max       int
+	data      []byte
+	truncated bool
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	if n := b.max - len(b.data); len(p) > n {
+		b.data = append(b.data, p[:n]...)
+		b.truncated = true
+	} else {
+		b.data = append(b.data, p...)
+	}
+
+	return len(p), nil
+}
+
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+// captureBody copy request body while it is read by handler,
+// so it can be recorded if the request is rejected.
+func captureBody(c *gin.Context) {
+	if !PushConf.Core.Rejected.Enabled {
+		return
+	}
+
+	buf := &bodyBuffer{max: PushConf.Core.Rejected.MaxBody}
+	c.Request.Body = teeBody{io.TeeReader(c.Request.Body, buf), c.Request.Body}
+	c.Set(rejectedBodyKey, buf)
+}
+
+// redactBody mask secret fields of JSON body, or device tokens and API keys
+// matched by pattern if body is not valid JSON (e.g. truncated).
+func redactBody(body string) string {
+	var value interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil {
+		if data, err := json.Marshal(redactJSON(value, false)); err == nil {
+			return string(data)
+		}
+	}
+
+	return secretPattern.ReplaceAllStringFunc(body, maskSecret)
+}
+
+// redactJSON mask string values of secret fields in decoded JSON.
+func redactJSON(value interface{}, secret bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if m, ok := item.(map[string]interface{}); ok && secretKeyFields[key] {
+				item = redactKeys(m)
+			}
+			v[key] = redactJSON(item, secret || secretFields[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, secret)
+		}
+	case string:
+		if secret {
+			return maskSecret(v)
+		}
+	}
+
+	return value
+}
+
+// redactKeys mask keys of decoded JSON object.
+func redactKeys(m map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, item := range m {
+		result[maskSecret(key)] = item
+	}
+
+	return result
+}
+
+// maskSecret keep only the first and last 4 characters of secret, short secret is fully masked.
+func maskSecret(s string) string {
+	if len(s) <= 12 {
+		return redactedValue
+	}
+
+	return s[:4] + "…" + s[len(s)-4:]
+}
+
+// recordRejected keep redacted body of captured request with rejection reason.
+func recordRejected(c *gin.Context, code int, reason string) {
+	value, ok := c.Get(rejectedBodyKey)
+	if !ok {
+		return
+	}
+
+	buf, _ := value.(*bodyBuffer)
+	if buf == nil {
+		return
+	}
+
+	addRejected(RejectedRequest{
+		Time:      time.Now().Unix(),
+		RequestID: c.Writer.Header().Get("X-Request-ID"),
+		Path:      c.Request.URL.Path,
+		Code:      code,
+		Reason:    reason,
+		Body:      redactBody(string(buf.data)),
+		Truncated: buf.truncated,
+	})
+}
+
+func addRejected(req RejectedRequest) {
+	rejectedLock.Lock()
+	defer rejectedLock.Unlock()
+
+	rejected = append(rejected, req)
+	if n := len(rejected) - PushConf.Core.Rejected.Size; n > 0 {
+		rejected = append([]RejectedRequest(nil), rejected[n:]...)
+	}
+}
+
+// RejectedRequests return samples of rejected requests, the newest first.
+func RejectedRequests() []RejectedRequest {
+	rejectedLock.Lock()
+	defer rejectedLock.Unlock()
+
+	result := make([]RejectedRequest, 0, len(rejected))
+	for i := len(rejected) - 1; i >= 0; i-- {
+		result = append(result, rejected[i])
+	}
+
+	return result
+}
+
+func rejectedHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"rejected": RejectedRequests(),
+	})
+}
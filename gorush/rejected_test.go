@@ -0,0 +1,54 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestBodyBuffer(t *testing.T) {
+	buf := &bodyBuffer{max: 5}
+
+	n, err := buf.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.False(t, buf.truncated)
+
+	n, err = buf.Write([]byte("defg"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "abcde", string(buf.data))
+	assert.True(t, buf.truncated)
+}
+
+func TestRedactBody(t *testing.T) {
+	token := "a1b2" + strings.Repeat("0", 56) + "f9e8"
+
+	assert.Equal(t, `{"message":"Hello","tokens":["a1b2…f9e8"]}`, redactBody(`{"tokens":["`+token+`"],"message":"Hello"}`))
+
+	// short tokens of providers and fields by name
+	body := `{"notifications":[{"tokens":["123456","https://hooks.slack.com/services/xxx"],"to":"/topics/news","api_key":"abc","message":"Hello"}]}`
+	assert.Equal(t, `{"notifications":[{"api_key":"******","message":"Hello","to":"******","tokens":["******","http…/xxx"]}]}`, redactBody(body))
+
+	// keys of refs are device tokens
+	assert.Equal(t, `{"refs":{"a1b2…f9e8":"user-1"},"tokens":["a1b2…f9e8"]}`, redactBody(`{"tokens":["`+token+`"],"refs":{"`+token+`":"user-1"}}`))
+
+	// truncated body is masked by pattern
+	assert.Equal(t, `{"tokens":["a1b2…f9e8`, redactBody(`{"tokens":["`+token))
+}
+
+func TestRejectedRequests(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Rejected.Size = 2
+	rejected = nil
+
+	addRejected(RejectedRequest{Reason: "a"})
+	addRejected(RejectedRequest{Reason: "b"})
+	addRejected(RejectedRequest{Reason: "c"})
+
+	result := RejectedRequests()
+	assert.Len(t, result, 2)
+	assert.Equal(t, "c", result[0].Reason)
+	assert.Equal(t, "b", result[1].Reason)
+}
@@ -0,0 +1,243 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Schema is subset of JSON schema to validate data field of notification,
+// supported keywords are type, required, properties, additionalProperties,
+// items, enum, minLength, maxLength, minimum and maximum.
+type Schema struct {
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+}
+
+var (
+	// schemas of data_schema files, key is file path.
+	schemas    = map[string]*Schema{}
+	schemaLock sync.RWMutex
+)
+
+// loadSchema read JSON schema file.
+func loadSchema(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("Can't parse schema %s: %v", path, err)
+	}
+
+	return schema, nil
+}
+
+// schemaPaths return data_schema files of config.
+func schemaPaths() []string {
+	var paths []string
+
+	if PushConf.Ios.DataSchema != "" {
+		paths = append(paths, PushConf.Ios.DataSchema)
+	}
+
+	for _, cert := range PushConf.Ios.Certs {
+		if cert.DataSchema != "" {
+			paths = append(paths, cert.DataSchema)
+		}
+	}
+
+	if PushConf.Android.DataSchema != "" {
+		paths = append(paths, PushConf.Android.DataSchema)
+	}
+
+	return paths
+}
+
+// InitSchemas load data schemas of apps.
+func InitSchemas() error {
+	loaded := map[string]*Schema{}
+	for _, path := range schemaPaths() {
+		schema, err := loadSchema(path)
+
+		if err != nil {
+			return err
+		}
+
+		loaded[path] = schema
+	}
+
+	schemaLock.Lock()
+	schemas = loaded
+	schemaLock.Unlock()
+
+	return nil
+}
+
+// schemaOf return data schema of app, schema of iOS certs is selected
+// by topic and default as data_schema of platform.
+func schemaOf(req PushNotification) *Schema {
+	var path string
+
+	switch req.Platform {
+	case PlatFormIos:
+		path = PushConf.Ios.DataSchema
+		for _, cert := range PushConf.Ios.Certs {
+			if cert.Topic == req.Topic && cert.DataSchema != "" {
+				path = cert.DataSchema
+				break
+			}
+		}
+	case PlatFormAndroid:
+		path = PushConf.Android.DataSchema
+	}
+
+	schemaLock.RLock()
+	defer schemaLock.RUnlock()
+
+	return schemas[path]
+}
+
+// CheckSchema validate data field of notification with schema of app.
+func CheckSchema(req PushNotification) error {
+	schema := schemaOf(req)
+	if schema == nil {
+		return nil
+	}
+
+	data := map[string]interface{}(req.Data)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	if errs := schema.validate("data", data); len(errs) > 0 {
+		return newMessageError(ErrCodeInvalidData, "data", "the data doesn't match schema of app: "+strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// typeOf return JSON schema type of decoded JSON value.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, D:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// validate return errors of value against schema, path is name of value in errors.
+func (s *Schema) validate(path string, value interface{}) []string {
+	var errs []string
+
+	kind := typeOf(value)
+	if s.Type != "" && s.Type != kind && !(s.Type == "number" && kind == "integer") {
+		return []string{fmt.Sprintf("%s must be %s, got %s", path, s.Type, kind)}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		errs = append(errs, fmt.Sprintf("%s must be one of %v", path, s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s must be at least %d characters", path, *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s must be at most %d characters", path, *s.MaxLength))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s must be greater than or equal to %v", path, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s must be less than or equal to %v", path, *s.Maximum))
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(path, v)...)
+	case D:
+		errs = append(errs, s.validateObject(path, v)...)
+	}
+
+	return errs
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}) []string {
+	var errs []string
+
+	for _, key := range s.Required {
+		if _, ok := object[key]; !ok {
+			errs = append(errs, fmt.Sprintf("%s.%s is required", path, key))
+		}
+	}
+
+	// sort keys for stable error message
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := s.Properties[key]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				errs = append(errs, fmt.Sprintf("%s.%s is not allowed", path, key))
+			}
+			continue
+		}
+
+		errs = append(errs, property.validate(path+"."+key, object[key])...)
+	}
+
+	return errs
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, v := range enum {
+		if fmt.Sprint(v) == fmt.Sprint(value) && typeOf(v) == typeOf(value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const testSchema = `{
+  "type": "object",
+  "required": ["order_id"],
+  "additionalProperties": false,
+  "properties": {
+    "order_id": {"type": "integer", "minimum": 1},
+    "status": {"type": "string", "enum": ["paid", "shipped"]},
+    "note": {"type": "string", "maxLength": 5},
+    "items": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func writeTestSchema(t *testing.T) string {
+	file, err := ioutil.TempFile("", "gorush-schema")
+	assert.NoError(t, err)
+	file.WriteString(testSchema)
+	file.Close()
+
+	return file.Name()
+}
+
+func TestSchemaValidate(t *testing.T) {
+	path := writeTestSchema(t)
+	defer os.Remove(path)
+
+	schema, err := loadSchema(path)
+	assert.NoError(t, err)
+
+	assert.Empty(t, schema.validate("data", map[string]interface{}{
+		"order_id": float64(10),
+		"status":   "paid",
+		"items":    []interface{}{"book"},
+	}))
+
+	errs := schema.validate("data", map[string]interface{}{
+		"order_id": 1.5,
+		"status":   "lost",
+		"note":     "too long",
+		"items":    []interface{}{"book", float64(1)},
+		"extra":    true,
+	})
+	assert.Equal(t, []string{
+		"data.extra is not allowed",
+		"data.items[1] must be string, got integer",
+		"data.note must be at most 5 characters",
+		"data.order_id must be integer, got number",
+		"data.status must be one of [paid shipped]",
+	}, errs)
+
+	errs = schema.validate("data", map[string]interface{}{
+		"order_id": float64(0),
+	})
+	assert.Equal(t, []string{"data.order_id must be greater than or equal to 1"}, errs)
+
+	errs = schema.validate("data", map[string]interface{}{})
+	assert.Equal(t, []string{"data.order_id is required"}, errs)
+}
+
+func TestCheckSchema(t *testing.T) {
+	path := writeTestSchema(t)
+	defer os.Remove(path)
+
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.schema", DataSchema: path},
+	}
+	assert.NoError(t, InitSchemas())
+	defer func() {
+		PushConf = config.BuildDefaultPushConf()
+		InitSchemas()
+	}()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormIos,
+		Topic:    "com.example.schema",
+		Message:  "Welcome",
+		Data:     D{"order_id": "10"},
+	}
+
+	err := CheckMessage(req)
+	assert.Error(t, err)
+	assert.Equal(t, ErrCodeInvalidData, err.(*MessageError).Code)
+	assert.Equal(t, "the data doesn't match schema of app: data.order_id must be integer, got string", err.Error())
+
+	req.Data = D{"order_id": float64(10)}
+	assert.NoError(t, CheckMessage(req))
+
+	// app without schema
+	req.Topic = "com.example.other"
+	req.Data = D{"order_id": "10"}
+	assert.NoError(t, CheckMessage(req))
+}
+
+func TestWrongSchemaConf(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+	PushConf.Android.DataSchema = "not-found.json"
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Can't load data schema")
+	assert.Error(t, InitSchemas())
+}
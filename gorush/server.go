@@ -1,14 +1,20 @@
 package gorush
 
 import (
+	"compress/gzip"
 	"fmt"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
 	"github.com/fvbock/endless"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	api "gopkg.in/appleboy/gin-status-api.v1"
 	"net/http"
+	"syscall"
 )
 
 func abortWithError(c *gin.Context, code int, message string) {
+	recordRejected(c, code, message)
 	c.JSON(code, gin.H{
 		"code":    code,
 		"message": message,
@@ -16,17 +22,90 @@ func abortWithError(c *gin.Context, code int, message string) {
 	c.Abort()
 }
 
+// abortWithMessageError response error with error code and field name of notification validation.
+func abortWithMessageError(c *gin.Context, code int, err error) {
+	msgErr, ok := err.(*MessageError)
+	if !ok {
+		abortWithError(c, code, err.Error())
+		return
+	}
+
+	recordRejected(c, code, msgErr.Message)
+	c.JSON(code, gin.H{
+		"code":       code,
+		"message":    msgErr.Message,
+		"error_code": msgErr.Code,
+		"field":      msgErr.Field,
+	})
+	c.Abort()
+}
+
+// jsonBinding decode request body with JSON serializer of build tag.
+type jsonBinding struct{}
+
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+func (jsonBinding) Bind(req *http.Request, obj interface{}) error {
+	if err := json.NewDecoder(req.Body).Decode(obj); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
 func rootHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"text": "Welcome to notification server.",
 	})
 }
 
+// traceHeaders return X-Request-ID and traceparent headers of request,
+// the request ID is echoed in response header.
+func traceHeaders(c *gin.Context) (string, string) {
+	requestID := c.Request.Header.Get("X-Request-ID")
+
+	// generate request ID to query push summary of request.
+	if requestID == "" {
+		requestID = NewUUID()
+	}
+
+	c.Header("X-Request-ID", requestID)
+
+	return requestID, c.Request.Header.Get("traceparent")
+}
+
 func pushHandler(c *gin.Context) {
 	var form RequestPush
 	var msg string
 
-	if err := c.BindJSON(&form); err != nil {
+	captureBody(c)
+
+	if max := PushConf.Core.MaxBodySize; max > 0 {
+		if c.Request.ContentLength > max {
+			msg = fmt.Sprintf("Request body size(%d) over limit(%d)", c.Request.ContentLength, max)
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusRequestEntityTooLarge, msg)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+	}
+
+	if PushConf.Core.StreamPush {
+		streamPushHandler(c)
+		return
+	}
+
+	if err := c.BindWith(&form, jsonBinding{}); err != nil {
+		if err.Error() == "http: request body too large" {
+			msg = fmt.Sprintf("Request body size over limit(%d)", PushConf.Core.MaxBodySize)
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusRequestEntityTooLarge, msg)
+			return
+		}
+
 		msg = "Missing notifications field."
 		LogAccess.Debug(msg)
 		abortWithError(c, http.StatusBadRequest, msg)
@@ -47,19 +126,236 @@ func pushHandler(c *gin.Context) {
 		return
 	}
 
+	var notifications []PushNotification
+	var invalidTokens []InvalidToken
+	var warnings []string
+	for i, notification := range form.Notifications {
+		valid, invalid, code, err := validateNotification(i, notification)
+
+		if err != nil {
+			err = notificationError(i, err)
+			LogAccess.Debug(err.Error())
+			abortWithMessageError(c, code, err)
+			return
+		}
+
+		for _, notification := range valid {
+			for _, warning := range LintMessage(notification) {
+				msg = fmt.Sprintf("notifications[%d]: %s", i, warning)
+				LogAccess.Warn(msg)
+				warnings = append(warnings, msg)
+			}
+		}
+
+		notifications = append(notifications, valid...)
+		invalidTokens = append(invalidTokens, invalid...)
+	}
+	form.Notifications = notifications
+
+	requestID, traceParent := traceHeaders(c)
+	for i := range form.Notifications {
+		form.Notifications[i].RequestID = requestID
+		form.Notifications[i].TraceParent = traceParent
+	}
+
 	// queue notification.
-	go queueNotification(form)
+	queueNotificationAsync(form)
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": "ok",
-	})
+	result := gin.H{
+		"success":    "ok",
+		"request_id": requestID,
+	}
+
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	if len(invalidTokens) > 0 {
+		result["invalid_tokens"] = invalidTokens
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func singlePushHandler(c *gin.Context) {
+	var notification PushNotification
+	var msg string
+
+	captureBody(c)
+
+	if err := c.BindWith(&notification, jsonBinding{}); err != nil {
+		msg = "Missing notification field."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	notification.RequestID, notification.TraceParent = traceHeaders(c)
+
+	result, err := PushSingle(notification)
+
+	if err != nil {
+		LogAccess.Debug(err.Error())
+		abortWithMessageError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	StatStorage.AddTotalCount(1)
+
+	c.JSON(http.StatusOK, result)
+}
+
+func previewHandler(c *gin.Context) {
+	var notification PushNotification
+	var msg string
+
+	if err := c.BindWith(&notification, jsonBinding{}); err != nil {
+		msg = "Missing notification field."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	result, err := GetPreview(notification)
+
+	if err != nil {
+		LogAccess.Debug(err.Error())
+		abortWithMessageError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GzipMiddleware decompress request body with gzip content encoding.
+func GzipMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Header.Get("Content-Encoding") != "gzip" {
+			c.Next()
+			return
+		}
+
+		reader, err := gzip.NewReader(c.Request.Body)
+
+		if err != nil {
+			msg := "Can't decompress gzip request body."
+			LogAccess.Debug(msg)
+			abortWithError(c, http.StatusBadRequest, msg)
+			return
+		}
+
+		defer reader.Close()
+
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Request.Body = reader
+
+		c.Next()
+	}
+}
+
+// redactedValue replace secrets of config.
+const redactedValue = "******"
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return redactedValue
+}
+
+// redactConf return copy of config without keys, passwords and webhook URLs.
+func redactConf(conf config.ConfYaml) config.ConfYaml {
+	conf.Core.Auth.JWTSecret = redact(conf.Core.Auth.JWTSecret)
+	keys := make([]config.SectionAuthKey, len(conf.Core.Auth.Keys))
+	for i, k := range conf.Core.Auth.Keys {
+		k.Key = redact(k.Key)
+		keys[i] = k
+	}
+	conf.Core.Auth.Keys = keys
+
+	conf.Android.APIKey = redact(conf.Android.APIKey)
+	conf.Android.Encrypt.Key = redact(conf.Android.Encrypt.Key)
+	conf.Ios.Password = redact(conf.Ios.Password)
+	conf.Ios.Encrypt.Key = redact(conf.Ios.Encrypt.Key)
+	certs := make([]config.SectionIosCert, len(conf.Ios.Certs))
+	for i, c := range conf.Ios.Certs {
+		c.Password = redact(c.Password)
+		certs[i] = c
+	}
+	conf.Ios.Certs = certs
+
+	conf.Ntfy.Token = redact(conf.Ntfy.Token)
+	conf.Telegram.BotToken = redact(conf.Telegram.BotToken)
+	conf.Slack.Webhooks = redactWebhooks(conf.Slack.Webhooks)
+	conf.Teams.Webhooks = redactWebhooks(conf.Teams.Webhooks)
+	conf.Stat.Redis.Password = redact(conf.Stat.Redis.Password)
+	conf.Inbound.Secret = redact(conf.Inbound.Secret)
+
+	return conf
+}
+
+func redactWebhooks(webhooks map[string]string) map[string]string {
+	result := make(map[string]string, len(webhooks))
+	for id, url := range webhooks {
+		result[id] = redact(url)
+	}
+
+	return result
 }
 
 func configHandler(c *gin.Context) {
-	c.YAML(http.StatusCreated, PushConf)
+	c.YAML(http.StatusCreated, redactConf(PushConf))
 }
 
-func routerEngine() *gin.Engine {
+// RegisterRoutes mount gorush middleware and API on router group, e.g. group of
+// path prefix in a larger service.
+func RegisterRoutes(r *gin.RouterGroup) {
+	r.Use(AccessMiddleware())
+	r.Use(VersionMiddleware())
+
+	// health check without auth.
+	r.GET("/", rootHandler)
+
+	r.Use(AuthMiddleware())
+	r.Use(LogMiddleware())
+	r.Use(StatMiddleware())
+	r.Use(GzipMiddleware())
+
+	handle(r, "GET", PushConf.API.StatGoURI, api.StatusHandler)
+	handle(r, "GET", PushConf.API.StatAppURI, appStatusHandler)
+	handle(r, "GET", PushConf.API.ConfigURI, requireAdmin(), configHandler)
+	handle(r, "GET", PushConf.API.SysStatURI, sysStatsHandler)
+	handle(r, "GET", PushConf.API.SummaryURI, summaryHandler)
+	handle(r, "GET", PushConf.API.EventsURI, requireAdmin(), eventsHandler)
+	handle(r, "GET", PushConf.API.RejectedURI, requireAdmin(), rejectedHandler)
+	handle(r, "POST", PushConf.API.PushURI, pushHandler)
+	handle(r, "POST", PushConf.API.SinglePushURI, singlePushHandler)
+	handle(r, "POST", PushConf.API.PreviewURI, previewHandler)
+	handle(r, "POST", PushConf.API.MaintenanceURI, requireAdmin(), maintenanceHandler)
+	handle(r, "POST", PushConf.API.AppURI, requireAdmin(), appHandler)
+	handle(r, "POST", PushConf.API.QueueExportURI, requireAdmin(), queueExportHandler)
+	handle(r, "POST", PushConf.API.QueueImportURI, requireAdmin(), queueImportHandler)
+
+	if PushConf.Inbound.Enabled {
+		handle(r, "POST", PushConf.API.InboundURI, inboundHandler)
+	}
+}
+
+// handle register route of API, API of empty uri is disabled.
+func handle(r *gin.RouterGroup, method, uri string, handlers ...gin.HandlerFunc) {
+	if uri == "" {
+		return
+	}
+
+	r.Handle(method, uri, handlers...)
+}
+
+// NewRouter create router of gorush API, middleware (e.g. auth or tracing)
+// is called before gorush middleware.
+func NewRouter(middleware ...gin.HandlerFunc) *gin.Engine {
 	// set server mode
 	gin.SetMode(PushConf.Core.Mode)
 
@@ -68,27 +364,29 @@ func routerEngine() *gin.Engine {
 	// Global middleware
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
-	r.Use(VersionMiddleware())
-	r.Use(LogMiddleware())
-	r.Use(StatMiddleware())
+	r.Use(middleware...)
 
-	r.GET(PushConf.API.StatGoURI, api.StatusHandler)
-	r.GET(PushConf.API.StatAppURI, appStatusHandler)
-	r.GET(PushConf.API.ConfigURI, configHandler)
-	r.GET(PushConf.API.SysStatURI, sysStatsHandler)
-	r.POST(PushConf.API.PushURI, pushHandler)
-	r.GET("/", rootHandler)
+	RegisterRoutes(&r.RouterGroup)
 
 	return r
 }
 
+func routerEngine() *gin.Engine {
+	return NewRouter()
+}
+
 // RunHTTPServer provide run http or https protocol.
 func RunHTTPServer() error {
 	var err error
+
+	server := endless.NewServer(":"+PushConf.Core.Port, routerEngine())
+	// new process is started on SIGHUP before old process exports its queue.
+	server.SignalHooks[endless.PRE_SIGNAL][syscall.SIGHUP] = append(server.SignalHooks[endless.PRE_SIGNAL][syscall.SIGHUP], beginHandover)
+
 	if PushConf.Core.SSL && PushConf.Core.CertPath != "" && PushConf.Core.KeyPath != "" {
-		err = endless.ListenAndServeTLS(":"+PushConf.Core.Port, PushConf.Core.CertPath, PushConf.Core.KeyPath, routerEngine())
+		err = server.ListenAndServeTLS(PushConf.Core.CertPath, PushConf.Core.KeyPath)
 	} else {
-		err = endless.ListenAndServe(":"+PushConf.Core.Port, routerEngine())
+		err = server.ListenAndServe()
 	}
 
 	return err
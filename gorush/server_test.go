@@ -1,6 +1,8 @@
 package gorush
 
 import (
+	"bytes"
+	"compress/gzip"
 	"github.com/appleboy/gorush/config"
 	"github.com/buger/jsonparser"
 	"github.com/gin-gonic/gin"
@@ -81,6 +83,42 @@ func TestRootHandler(t *testing.T) {
 		})
 }
 
+func TestNewRouterMiddleware(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	router := NewRouter(func(c *gin.Context) {
+		c.Header("X-Embed", "gorush")
+		c.Next()
+	})
+
+	r.GET("/api/stat/go").
+		Run(router, func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, "gorush", r.HeaderMap.Get("X-Embed"))
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
+
+func TestRegisterRoutesPrefix(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	router := gin.New()
+	RegisterRoutes(router.Group("/push"))
+
+	r.GET("/push/api/stat/go").
+		Run(router, func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			data := []byte(r.Body.String())
+
+			value, _ := jsonparser.GetString(data, "go_version")
+
+			assert.Equal(t, goVersion, value)
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
+
 func TestAPIStatusGoHandler(t *testing.T) {
 	initTest()
 
@@ -119,9 +157,17 @@ func TestAPIStatusAppHandler(t *testing.T) {
 func TestAPIConfigHandler(t *testing.T) {
 	initTest()
 
+	PushConf.Core.Auth.Engine = "static"
+	PushConf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "admin", Key: "secret", Scopes: []string{"admin"}},
+	}
+
 	r := gofight.New()
 
 	r.GET("/api/config").
+		SetHeader(gofight.H{
+			"Authorization": "Bearer secret",
+		}).
 		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
 			assert.Equal(t, http.StatusCreated, r.Code)
 		})
@@ -211,6 +257,286 @@ func TestSuccessPushHandler(t *testing.T) {
 		})
 }
 
+func TestOverBodySizeLimit(t *testing.T) {
+	initTest()
+
+	PushConf.Core.MaxBodySize = 10
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetJSON(gofight.D{
+			"notifications": []gofight.D{
+				{
+					"tokens":   []string{"aaaaa"},
+					"platform": PlatFormIos,
+					"message":  "Welcome",
+				},
+			},
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusRequestEntityTooLarge, r.Code)
+		})
+}
+
+func TestOverTokensLimit(t *testing.T) {
+	initTest()
+
+	PushConf.Core.MaxTokens = 1
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetJSON(gofight.D{
+			"notifications": []gofight.D{
+				{
+					"tokens":   []string{"aaaaa", "bbbbb"},
+					"platform": PlatFormIos,
+					"message":  "Welcome",
+				},
+			},
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "notifications[0]: number of tokens(2) over limit(1)", value)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, r.Code)
+		})
+}
+
+func TestStreamPushHandler(t *testing.T) {
+	initTest()
+
+	PushConf.Core.StreamPush = true
+	PushConf.Android.Enabled = true
+	PushConf.Android.APIKey = "xxxxx"
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetBody(`{"notifications":[{"tokens":["aaaaa"],"platform":2,"message":"Welcome"}]}`).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+
+	r.POST("/api/push").
+		SetBody(`{"notifications":[]}`).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Notifications field is empty.", value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+
+	// invalid notification is rejected like pushHandler, earlier notification is queued.
+	r.POST("/api/push").
+		SetBody(`{"notifications":[{"tokens":["aaaaa"],"platform":2,"message":"Welcome"},{"tokens":["aaaaa"],"platform":2}]}`).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			code, _ := jsonparser.GetString(r.Body.Bytes(), "error_code")
+			queued, _ := jsonparser.GetInt(r.Body.Bytes(), "queued")
+
+			assert.Equal(t, ErrCodeEmptyMessage, code)
+			assert.Equal(t, int64(1), queued)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+
+	PushConf.Core.MaxNotification = 1
+
+	r.POST("/api/push").
+		SetBody(`{"notifications":[{"tokens":["aaaaa"],"platform":2,"message":"Welcome"},{"tokens":["aaaaa"],"platform":2,"message":"Welcome"}]}`).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+			queued, _ := jsonparser.GetInt(r.Body.Bytes(), "queued")
+
+			assert.Equal(t, "Number of notifications over limit(1)", value)
+			assert.Equal(t, int64(1), queued)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+}
+
+func TestSinglePushHandler(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	// iOS is not enabled
+	r.POST("/api/push/single").
+		SetJSON(gofight.D{
+			"tokens":   []string{"aaaaa"},
+			"platform": PlatFormIos,
+			"message":  "Welcome",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "iOS platform is not enabled", value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+
+	r.POST("/api/push/single").
+		SetJSON(gofight.D{
+			"tokens":   []string{"aaaaa", "bbbbb"},
+			"platform": PlatFormIos,
+			"message":  "Welcome",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "single push must specify exactly one token", value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+
+	r.POST("/api/push/single").
+		SetJSON(gofight.D{
+			"tokens":   []string{"aaaaa"},
+			"platform": PlatFormIos,
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			code, _ := jsonparser.GetString(r.Body.Bytes(), "error_code")
+			field, _ := jsonparser.GetString(r.Body.Bytes(), "field")
+
+			assert.Equal(t, ErrCodeEmptyMessage, code)
+			assert.Equal(t, "message", field)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+}
+
+func TestPushHandlerRequestID(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetHeader(gofight.H{
+			"X-Request-ID": "request-1",
+		}).
+		SetJSON(gofight.D{
+			"notifications": []gofight.D{
+				{
+					"tokens":   []string{"aaaaa"},
+					"platform": PlatFormIos,
+					"message":  "Welcome",
+				},
+			},
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, "request-1", r.HeaderMap.Get("X-Request-ID"))
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
+
+func TestPushHandlerWarnings(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetJSON(gofight.D{
+			"notifications": []gofight.D{
+				{
+					"tokens":            []string{"aaaaa"},
+					"platform":          PlatFormIos,
+					"message":           "Welcome",
+					"content_available": true,
+				},
+			},
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Contains(t, r.Body.String(), "notifications[0]: background notification with content_available should use normal priority")
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
+
+func TestDisabledAppPushHandler(t *testing.T) {
+	initTest()
+	InitLog()
+	InitApps()
+
+	SetApp("com.example.app", false, false)
+	defer SetApp("com.example.app", true, false)
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetJSON(gofight.D{
+			"notifications": []gofight.D{
+				{
+					"tokens":   []string{"aaaaa"},
+					"platform": PlatFormIos,
+					"topic":    "com.example.app",
+					"message":  "Welcome",
+				},
+			},
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "notifications[0]: app com.example.app is disabled", value)
+			assert.Equal(t, http.StatusForbidden, r.Code)
+		})
+}
+
+func TestQueueExportHandler(t *testing.T) {
+	initTest()
+	InitLog()
+
+	PushConf.Core.Auth.Engine = "static"
+	PushConf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "admin", Key: "secret", Scopes: []string{"admin"}},
+	}
+
+	r := gofight.New()
+
+	r.POST("/api/queue/export").
+		SetHeader(gofight.H{
+			"Authorization": "Bearer secret",
+		}).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Export is only allowed in maintenance mode.", value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+}
+
+func TestGzipPushHandler(t *testing.T) {
+	initTest()
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write([]byte(`{"notifications":[{"tokens":["aaaaa"],"platform":1,"message":"Welcome"}]}`))
+	gz.Close()
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetHeader(gofight.H{
+			"Content-Encoding": "gzip",
+		}).
+		SetBody(body.String()).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			assert.Equal(t, http.StatusOK, r.Code)
+		})
+}
+
+func TestWrongGzipPushHandler(t *testing.T) {
+	initTest()
+
+	r := gofight.New()
+
+	r.POST("/api/push").
+		SetHeader(gofight.H{
+			"Content-Encoding": "gzip",
+		}).
+		SetBody(`{"notifications":[]}`).
+		Run(routerEngine(), func(r gofight.HTTPResponse, rq gofight.HTTPRequest) {
+			value, _ := jsonparser.GetString(r.Body.Bytes(), "message")
+
+			assert.Equal(t, "Can't decompress gzip request body.", value)
+			assert.Equal(t, http.StatusBadRequest, r.Code)
+		})
+}
+
 func TestSysStatsHandler(t *testing.T) {
 	initTest()
 
@@ -221,3 +547,40 @@ func TestSysStatsHandler(t *testing.T) {
 			assert.Equal(t, http.StatusOK, r.Code)
 		})
 }
+
+func TestRedactConf(t *testing.T) {
+	conf := config.BuildDefaultPushConf()
+	conf.Core.Auth.JWTSecret = "jwt"
+	conf.Core.Auth.Keys = []config.SectionAuthKey{
+		{Name: "backend", Key: "secret"},
+	}
+	conf.Android.APIKey = "apikey"
+	conf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.app", Password: "password"},
+	}
+	conf.Slack.Webhooks = map[string]string{"ops": "https://hooks.slack.com/services/T/B/X"}
+
+	result := redactConf(conf)
+
+	assert.Equal(t, redactedValue, result.Core.Auth.JWTSecret)
+	assert.Equal(t, redactedValue, result.Core.Auth.Keys[0].Key)
+	assert.Equal(t, "backend", result.Core.Auth.Keys[0].Name)
+	assert.Equal(t, redactedValue, result.Android.APIKey)
+	assert.Equal(t, redactedValue, result.Ios.Certs[0].Password)
+	assert.Equal(t, redactedValue, result.Slack.Webhooks["ops"])
+	assert.Equal(t, "", result.Ios.Password)
+
+	// original config is kept.
+	assert.Equal(t, "secret", conf.Core.Auth.Keys[0].Key)
+	assert.Equal(t, "password", conf.Ios.Certs[0].Password)
+}
+
+func TestEmptyAPIURI(t *testing.T) {
+	initTest()
+
+	PushConf.API.SinglePushURI = ""
+	PushConf.API.EventsURI = ""
+
+	// gin panics on route of empty path.
+	routerEngine()
+}
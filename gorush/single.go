@@ -0,0 +1,52 @@
+package gorush
+
+import "errors"
+
+// SingleResponse is raw provider response of single push.
+type SingleResponse struct {
+	Platform   string `json:"platform"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code"`
+	Reason     string `json:"reason,omitempty"`
+	ApnsID     string `json:"apns_id,omitempty"`
+	MessageID  string `json:"message_id,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	// Latency is milliseconds of provider call.
+	Latency int64 `json:"latency"`
+}
+
+// PushSingle send notification of one token immediately without queue.
+func PushSingle(req PushNotification) (*SingleResponse, error) {
+	if len(req.Tokens) > 1 {
+		return nil, newMessageError(ErrCodeTooManyTokens, "tokens", "single push must specify exactly one token")
+	}
+
+	notifications, invalid, _, err := validateNotification(0, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invalid) > 0 {
+		return nil, newMessageError(invalid[0].ErrorCode, "tokens", invalid[0].Error)
+	}
+
+	req = notifications[0]
+
+	switch req.Platform {
+	case PlatFormIos:
+		if !PushConf.Ios.Enabled {
+			return nil, errors.New("iOS platform is not enabled")
+		}
+
+		return pushSingleIOS(req), nil
+	case PlatFormAndroid:
+		if !PushConf.Android.Enabled {
+			return nil, errors.New("Android platform is not enabled")
+		}
+
+		return pushSingleAndroid(req), nil
+	default:
+		return nil, errors.New("single push only support iOS and Android platform")
+	}
+}
@@ -0,0 +1,38 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPushSingleWrongRequest(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa", "bbbbb"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+	}
+
+	_, err := PushSingle(req)
+	assert.Equal(t, "single push must specify exactly one token", err.Error())
+
+	req.Tokens = []string{"aaaaa"}
+	_, err = PushSingle(req)
+	assert.Equal(t, "Android platform is not enabled", err.Error())
+
+	req.Message = ""
+	_, err = PushSingle(req)
+	assert.Equal(t, "the message must not be empty", err.Error())
+
+	req.Message = "Welcome"
+	req.Tokens = nil
+	_, err = PushSingle(req)
+	assert.Equal(t, ErrCodeMissingToken, err.(*MessageError).Code)
+
+	req.Tokens = []string{"aaaaa"}
+	req.Platform = PlatFormNtfy
+	_, err = PushSingle(req)
+	assert.Equal(t, "single push only support iOS and Android platform", err.Error())
+}
@@ -0,0 +1,70 @@
+package gorush
+
+// SlackText is text object of Slack block.
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackBlock is layout block of Slack message.
+type SlackBlock struct {
+	Type string     `json:"type"`
+	Text *SlackText `json:"text,omitempty"`
+}
+
+// SlackMessage is request body of Slack incoming webhook.
+// ref: https://api.slack.com/messaging/webhooks
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+// GetSlackMessage use for define Slack message, title is rendered as header block.
+func GetSlackMessage(req PushNotification) SlackMessage {
+	message := SlackMessage{
+		Text: req.Message,
+	}
+
+	if len(req.Title) > 0 {
+		message.Blocks = append(message.Blocks, SlackBlock{
+			Type: "header",
+			Text: &SlackText{Type: "plain_text", Text: req.Title},
+		})
+	}
+
+	message.Blocks = append(message.Blocks, SlackBlock{
+		Type: "section",
+		Text: &SlackText{Type: "mrkdwn", Text: req.Message},
+	})
+
+	return message
+}
+
+// PushToSlack provide send notification to Slack, tokens are webhook URLs or IDs.
+func PushToSlack(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for Slack")
+
+	var isError bool
+
+	message := GetSlackMessage(req)
+
+	for _, token := range req.Tokens {
+		url, err := webhookURL(PushConf.Slack.Webhooks, token)
+
+		if err == nil {
+			err = postJSON(url, nil, message)
+		}
+
+		if err != nil {
+			LogPush(FailedPush, token, req, err)
+			StatStorage.AddProviderError("slack", 1)
+			isError = true
+			continue
+		}
+
+		LogPush(SucceededPush, token, req, nil)
+		StatStorage.AddProviderSuccess("slack", 1)
+	}
+
+	return isError
+}
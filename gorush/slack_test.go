@@ -0,0 +1,93 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookURL(t *testing.T) {
+	webhooks := map[string]string{
+		"ops": "https://hooks.slack.com/services/xxx",
+	}
+
+	url, err := webhookURL(webhooks, "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/xxx", url)
+
+	url, err = webhookURL(webhooks, "https://hooks.slack.com/services/xxx")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/xxx", url)
+
+	// URL not in config
+	_, err = webhookURL(webhooks, "http://169.254.169.254/latest")
+	assert.Error(t, err)
+
+	_, err = webhookURL(webhooks, "dev")
+	assert.Equal(t, "webhook dev is not found", err.Error())
+}
+
+func TestPostJSONTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	timeout := providerClient.Timeout
+	providerClient.Timeout = 50 * time.Millisecond
+	defer func() {
+		providerClient.Timeout = timeout
+	}()
+
+	assert.Error(t, postJSON(ts.URL, nil, SlackMessage{Text: "Welcome"}))
+}
+
+func TestSlackMessage(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"ops"},
+		Platform: PlatFormSlack,
+		Message:  "Welcome",
+		Title:    "Gorush",
+	}
+
+	message := GetSlackMessage(req)
+
+	assert.Equal(t, "Welcome", message.Text)
+	assert.Equal(t, 2, len(message.Blocks))
+	assert.Equal(t, "header", message.Blocks[0].Type)
+	assert.Equal(t, "Gorush", message.Blocks[0].Text.Text)
+	assert.Equal(t, "section", message.Blocks[1].Type)
+	assert.Equal(t, "Welcome", message.Blocks[1].Text.Text)
+}
+
+func TestPushToSlack(t *testing.T) {
+	var message SlackMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Slack.Enabled = true
+	InitAppStatus()
+	PushConf.Slack.Webhooks = map[string]string{
+		"ops": ts.URL,
+	}
+
+	req := PushNotification{
+		Tokens:   []string{"ops"},
+		Platform: PlatFormSlack,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, PushToSlack(req))
+	assert.Equal(t, "Welcome", message.Text)
+
+	req.Tokens = []string{"dev"}
+	assert.True(t, PushToSlack(req))
+}
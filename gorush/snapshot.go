@@ -0,0 +1,278 @@
+package gorush
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// errQueueFull is returned by RestoreQueue if there is no free space of queue.
+var errQueueFull = errors.New("no free space of queue")
+
+// QueueSnapshot is pending notifications of queue for export and import.
+type QueueSnapshot struct {
+	Notifications []PushNotification `json:"notifications"`
+}
+
+// ImportResult is response of queue import, remaining notifications are not
+// imported if queue is full.
+type ImportResult struct {
+	Imported   int `json:"imported"`
+	Remaining  int `json:"remaining,omitempty"`
+	QueueUsage int `json:"queue_usage"`
+}
+
+// snapshotNotification is notification in snapshot with fields which are not
+// accepted by push API but set before it is queued.
+type snapshotNotification struct {
+	PushNotification
+	Variant     string `json:"variant,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+func newSnapshotNotification(notification PushNotification) snapshotNotification {
+	return snapshotNotification{
+		PushNotification: notification,
+		Variant:          notification.Variant,
+		RequestID:        notification.RequestID,
+		TraceParent:      notification.TraceParent,
+	}
+}
+
+func (s snapshotNotification) notification() PushNotification {
+	notification := s.PushNotification
+	notification.Variant = s.Variant
+	notification.RequestID = s.RequestID
+	notification.TraceParent = s.TraceParent
+
+	return notification
+}
+
+type queueSnapshotJSON struct {
+	Notifications []snapshotNotification `json:"notifications"`
+}
+
+// MarshalJSON keep request ID, trace parent and variant of notifications.
+func (s QueueSnapshot) MarshalJSON() ([]byte, error) {
+	form := queueSnapshotJSON{
+		Notifications: make([]snapshotNotification, 0, len(s.Notifications)),
+	}
+	for _, notification := range s.Notifications {
+		form.Notifications = append(form.Notifications, newSnapshotNotification(notification))
+	}
+
+	return json.Marshal(form)
+}
+
+// UnmarshalJSON restore request ID, trace parent and variant of notifications.
+func (s *QueueSnapshot) UnmarshalJSON(data []byte) error {
+	var form queueSnapshotJSON
+	if err := json.Unmarshal(data, &form); err != nil {
+		return err
+	}
+
+	s.Notifications = nil
+	for _, notification := range form.Notifications {
+		s.Notifications = append(s.Notifications, notification.notification())
+	}
+
+	return nil
+}
+
+// WriteSnapshot write notifications into writer, one JSON per line.
+func WriteSnapshot(w io.Writer, notifications []PushNotification) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	for _, notification := range notifications {
+		if err := encoder.Encode(newSnapshotNotification(notification)); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// ReadSnapshot read notifications from reader, one JSON per line.
+func ReadSnapshot(r io.Reader) ([]PushNotification, error) {
+	var notifications []PushNotification
+
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var notification snapshotNotification
+		if err := decoder.Decode(&notification); err != nil {
+			return nil, fmt.Errorf("Can't parse notification %d: %v", len(notifications)+1, err)
+		}
+
+		notifications = append(notifications, notification.notification())
+	}
+
+	return notifications, nil
+}
+
+// writeSnapshotFile replace file with notifications, one JSON per line.
+func writeSnapshotFile(path string, notifications []PushNotification) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+
+	if err != nil {
+		return err
+	}
+
+	err = WriteSnapshot(file, notifications)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// appendSnapshot append notifications into file, one JSON per line.
+func appendSnapshot(path string, notifications []PushNotification) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return WriteSnapshot(file, notifications)
+}
+
+// tryDequeue dequeue notification without blocking if queue engine supports it.
+func tryDequeue() (PushNotification, bool, error) {
+	if queue, ok := QueueNotification.(QueueTryDequeue); ok {
+		return queue.TryDequeue()
+	}
+
+	notification, err := QueueNotification.Dequeue()
+
+	return notification, err == nil, err
+}
+
+// SnapshotQueue dequeue all pending notifications of queue in maintenance mode.
+// Workers blocked in Dequeue put notifications back in maintenance mode,
+// so queue is drained until it stays empty for maintenanceWait.
+func SnapshotQueue() ([]PushNotification, error) {
+	var notifications []PushNotification
+
+	for {
+		if QueueNotification.Len() == 0 {
+			time.Sleep(maintenanceWait)
+
+			if QueueNotification.Len() == 0 {
+				return notifications, nil
+			}
+		}
+
+		notification, ok, err := tryDequeue()
+
+		if err != nil {
+			return notifications, err
+		}
+
+		if !ok {
+			// notification is being put back by worker.
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		QueueNotification.Ack(notification)
+		notifications = append(notifications, notification)
+	}
+}
+
+// RestoreQueue enqueue exported notifications in order until queue is full,
+// notifications of auto platform are split by token format. It return number of
+// restored notifications, the rest are not queued if error is returned.
+func RestoreQueue(notifications []PushNotification) (int, error) {
+	for i, notification := range notifications {
+		resolved, invalid := resolveAuto([]PushNotification{notification})
+		for _, token := range invalid {
+			masked := logToken(maskToken(token.Token, notification))
+			LogError.Error(fmt.Sprintf("drop token %s of notifications[%d]: %s", masked, i, token.Error))
+		}
+
+		if max := int(PushConf.Core.QueueNum); max > 0 && QueueNotification.Len()+len(resolved) > max {
+			return i, errQueueFull
+		}
+
+		for _, req := range resolved {
+			if err := QueueNotification.Enqueue(req); err != nil {
+				return i, err
+			}
+		}
+	}
+
+	return len(notifications), nil
+}
+
+func queueExportHandler(c *gin.Context) {
+	var msg string
+
+	if !IsMaintenance() {
+		msg = "Export is only allowed in maintenance mode."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	notifications, err := SnapshotQueue()
+
+	if err != nil {
+		// put back dequeued notifications
+		RestoreQueue(notifications)
+		msg = "Can't export queue: " + err.Error()
+		LogError.Error(msg)
+		abortWithError(c, http.StatusInternalServerError, msg)
+		return
+	}
+
+	LogAccess.Info("export ", len(notifications), " notifications of queue")
+
+	c.JSON(http.StatusOK, QueueSnapshot{
+		Notifications: notifications,
+	})
+}
+
+func queueImportHandler(c *gin.Context) {
+	var form QueueSnapshot
+	var msg string
+
+	if err := c.BindWith(&form, jsonBinding{}); err != nil {
+		msg = "Missing notifications field."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	count, err := RestoreQueue(form.Notifications)
+
+	if err != nil && count == 0 {
+		msg = "Can't import queue: " + err.Error()
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	remaining := len(form.Notifications) - count
+	LogAccess.Info("import ", count, " notifications into queue, ", remaining, " remaining")
+
+	c.JSON(http.StatusOK, ImportResult{
+		Imported:   count,
+		Remaining:  remaining,
+		QueueUsage: QueueNotification.Len(),
+	})
+}
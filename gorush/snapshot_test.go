@@ -0,0 +1,130 @@
+package gorush
+
+import (
+	"bytes"
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAndReadSnapshot(t *testing.T) {
+	notifications := []PushNotification{
+		{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"},
+		{Tokens: []string{"bbbbb"}, Platform: PlatFormAndroid, Message: "World", RequestID: "123", TraceParent: "00-abc-def-01", Variant: "a"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSnapshot(&buf, notifications))
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	result, err := ReadSnapshot(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "World", result[1].Message)
+	assert.Equal(t, PlatFormAndroid, result[1].Platform)
+	assert.Equal(t, "123", result[1].RequestID)
+	assert.Equal(t, "00-abc-def-01", result[1].TraceParent)
+	assert.Equal(t, "a", result[1].Variant)
+
+	_, err = ReadSnapshot(bytes.NewBufferString("{\"message\":\"Hello\"}\n{wrong"))
+	assert.Error(t, err)
+}
+
+func TestQueueSnapshotJSON(t *testing.T) {
+	data, err := json.Marshal(QueueSnapshot{
+		Notifications: []PushNotification{
+			{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello", RequestID: "123", Variant: "a"},
+		},
+	})
+	assert.NoError(t, err)
+
+	var snapshot QueueSnapshot
+	assert.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.Len(t, snapshot.Notifications, 1)
+	assert.Equal(t, "Hello", snapshot.Notifications[0].Message)
+	assert.Equal(t, "123", snapshot.Notifications[0].RequestID)
+	assert.Equal(t, "a", snapshot.Notifications[0].Variant)
+}
+
+func TestSnapshotAndRestoreQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.QueueNum = 2
+	QueueNotification = newChannelQueue(2, 0)
+
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"})
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"bbbbb"}, Platform: PlatFormAndroid, Message: "World"})
+
+	notifications, err := SnapshotQueue()
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, 0, QueueNotification.Len())
+
+	count, err := RestoreQueue(notifications)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, QueueNotification.Len())
+
+	// over free space of queue
+	count, err = RestoreQueue(notifications[:1])
+	assert.Equal(t, errQueueFull, err)
+	assert.Equal(t, 0, count)
+
+	// restore until queue is full
+	QueueNotification.Dequeue()
+	count, err = RestoreQueue(notifications)
+	assert.Equal(t, errQueueFull, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 2, QueueNotification.Len())
+}
+
+func TestSnapshotQueueWithBlockedWorker(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	QueueNotification = newChannelQueue(10, 0)
+	defer SetMaintenance(false)
+
+	// worker blocked in Dequeue before maintenance mode put notification back.
+	done := make(chan bool)
+	go func() {
+		notification, _ := QueueNotification.Dequeue()
+		if IsMaintenance() {
+			QueueNotification.Nack(notification)
+		}
+		done <- true
+	}()
+
+	SetMaintenance(true)
+	time.Sleep(10 * time.Millisecond)
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"aaaaa"}, Platform: PlatFormIos, Message: "Hello"})
+	QueueNotification.Enqueue(PushNotification{Tokens: []string{"bbbbb"}, Platform: PlatFormIos, Message: "World"})
+
+	notifications, err := SnapshotQueue()
+	<-done
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, 0, QueueNotification.Len())
+}
+
+func TestRestoreAutoQueue(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	QueueNotification = newChannelQueue(10, 0)
+
+	count, err := RestoreQueue([]PushNotification{
+		{
+			Tokens:   []string{strings.Repeat("a", 64), "dQw4w9WgXcQ:APA91bH-" + strings.Repeat("a", 140), "bbbbb"},
+			Platform: PlatFormAuto,
+			Message:  "Hello",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 2, QueueNotification.Len())
+
+	notification, _ := QueueNotification.Dequeue()
+	assert.Equal(t, PlatFormIos, notification.Platform)
+	notification, _ = QueueNotification.Dequeue()
+	assert.Equal(t, PlatFormAndroid, notification.Platform)
+}
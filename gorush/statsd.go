@@ -0,0 +1,93 @@
+package gorush
+
+import (
+	"fmt"
+	"github.com/appleboy/gorush/config"
+	"net"
+	"strings"
+)
+
+// StatsdStorage send counters to StatsD server and record them to the wrapped storage.
+type StatsdStorage struct {
+	Storage
+	conn   net.Conn
+	prefix string
+	tags   string
+}
+
+// NewStatsdStorage create StatsD emitter for storage.
+func NewStatsdStorage(storage Storage, conf config.SectionStatsd) (*StatsdStorage, error) {
+	conn, err := net.Dial("udp", conf.Addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StatsdStorage{
+		Storage: storage,
+		conn:    conn,
+		prefix:  conf.Prefix,
+	}
+
+	if s.prefix != "" && !strings.HasSuffix(s.prefix, ".") {
+		s.prefix += "."
+	}
+
+	// DogStatsD tags
+	if len(conf.Tags) > 0 {
+		s.tags = "|#" + strings.Join(conf.Tags, ",")
+	}
+
+	return s, nil
+}
+
+func (s *StatsdStorage) count(name string, count int64) {
+	// ignore the error, metrics must not block push.
+	fmt.Fprintf(s.conn, "%s%s:%d|c%s", s.prefix, name, count, s.tags)
+}
+
+func (s *StatsdStorage) gauge(name string, value int64) {
+	fmt.Fprintf(s.conn, "%s%s:%d|g%s", s.prefix, name, value, s.tags)
+}
+
+// AddTotalCount record push notification count.
+func (s *StatsdStorage) AddTotalCount(count int64) {
+	s.Storage.AddTotalCount(count)
+	s.count("total_count", count)
+}
+
+// AddIosSuccess record counts of success iOS push notification.
+func (s *StatsdStorage) AddIosSuccess(count int64) {
+	s.Storage.AddIosSuccess(count)
+	s.count("ios.push_success", count)
+}
+
+// AddIosError record counts of error iOS push notification.
+func (s *StatsdStorage) AddIosError(count int64) {
+	s.Storage.AddIosError(count)
+	s.count("ios.push_error", count)
+}
+
+// AddAndroidSuccess record counts of success Android push notification.
+func (s *StatsdStorage) AddAndroidSuccess(count int64) {
+	s.Storage.AddAndroidSuccess(count)
+	s.count("android.push_success", count)
+}
+
+// AddAndroidError record counts of error Android push notification.
+func (s *StatsdStorage) AddAndroidError(count int64) {
+	s.Storage.AddAndroidError(count)
+	s.count("android.push_error", count)
+}
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *StatsdStorage) AddProviderSuccess(name string, count int64) {
+	s.Storage.AddProviderSuccess(name, count)
+	s.count(name+".push_success", count)
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *StatsdStorage) AddProviderError(name string, count int64) {
+	s.Storage.AddProviderError(name, count)
+	s.count(name+".push_error", count)
+}
@@ -0,0 +1,43 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsdStorage(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	s, err := NewStatsdStorage(memory.New(), config.SectionStatsd{
+		Addr:   conn.LocalAddr().String(),
+		Prefix: "gorush",
+		Tags:   []string{"env:test", "app:demo"},
+	})
+	assert.NoError(t, err)
+
+	s.AddIosSuccess(2)
+
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gorush.ios.push_success:2|c|#env:test,app:demo", string(buf[:n]))
+	assert.Equal(t, int64(2), s.GetIosSuccess())
+}
+
+func TestStatsdEngine(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Stat.Statsd.Enabled = true
+
+	assert.NoError(t, InitAppStatus())
+
+	_, ok := StatStorage.(*StatsdStorage)
+	assert.True(t, ok)
+}
@@ -1,11 +1,7 @@
 package gorush
 
 import (
-	"github.com/appleboy/gorush/storage/boltdb"
-	"github.com/appleboy/gorush/storage/buntdb"
-	"github.com/appleboy/gorush/storage/leveldb"
-	"github.com/appleboy/gorush/storage/memory"
-	"github.com/appleboy/gorush/storage/redis"
+	"github.com/appleboy/gorush/config"
 	"github.com/gin-gonic/gin"
 	"github.com/thoas/stats"
 	"net/http"
@@ -16,42 +12,42 @@ var Stats = stats.New()
 
 // StatusApp is app status structure
 type StatusApp struct {
-	Version    string        `json:"version"`
-	QueueMax   int           `json:"queue_max"`
-	QueueUsage int           `json:"queue_usage"`
-	TotalCount int64         `json:"total_count"`
-	Ios        IosStatus     `json:"ios"`
-	Android    AndroidStatus `json:"android"`
+	Version     string                    `json:"version"`
+	QueueMax    int                       `json:"queue_max"`
+	QueueUsage  int                       `json:"queue_usage"`
+	QueueAge    map[string]int64          `json:"queue_age,omitempty"`
+	TotalCount  int64                     `json:"total_count"`
+	Ios         IosStatus                 `json:"ios"`
+	Android     AndroidStatus             `json:"android"`
+	Providers   map[string]ProviderStatus `json:"providers"`
+	Maintenance bool                      `json:"maintenance"`
 }
 
 // AndroidStatus is android structure
 type AndroidStatus struct {
 	PushSuccess int64 `json:"push_success"`
 	PushError   int64 `json:"push_error"`
+	Paused      bool  `json:"paused"`
+	FailedOver  bool  `json:"failed_over"`
+}
+
+// ProviderStatus is structure of webhook provider, e.g. slack
+type ProviderStatus struct {
+	PushSuccess int64 `json:"push_success"`
+	PushError   int64 `json:"push_error"`
 }
 
 // IosStatus is iOS structure
 type IosStatus struct {
 	PushSuccess int64 `json:"push_success"`
 	PushError   int64 `json:"push_error"`
+	Paused      bool  `json:"paused"`
+	FailedOver  bool  `json:"failed_over"`
 }
 
 // InitAppStatus for initialize app status
 func InitAppStatus() error {
-	switch PushConf.Stat.Engine {
-	case "memory":
-		StatStorage = memory.New()
-	case "redis":
-		StatStorage = redis.New(PushConf)
-	case "boltdb":
-		StatStorage = boltdb.New(PushConf)
-	case "buntdb":
-		StatStorage = buntdb.New(PushConf)
-	case "leveldb":
-		StatStorage = leveldb.New(PushConf)
-	default:
-		StatStorage = memory.New()
-	}
+	StatStorage = newStatStorage(PushConf)
 
 	err := StatStorage.Init()
 
@@ -61,6 +57,18 @@ func InitAppStatus() error {
 		return err
 	}
 
+	if PushConf.Stat.Statsd.Enabled {
+		statsd, err := NewStatsdStorage(StatStorage, PushConf.Stat.Statsd)
+
+		if err != nil {
+			LogError.Error("statsd error: " + err.Error())
+
+			return err
+		}
+
+		StatStorage = statsd
+	}
+
 	return nil
 }
 
@@ -68,13 +76,28 @@ func appStatusHandler(c *gin.Context) {
 	result := StatusApp{}
 
 	result.Version = GetVersion()
-	result.QueueMax = cap(QueueNotification)
-	result.QueueUsage = len(QueueNotification)
+	result.QueueMax = int(PushConf.Core.QueueNum)
+	if QueueNotification != nil {
+		result.QueueUsage = QueueNotification.Len()
+		result.QueueAge = queueAges()
+	}
 	result.TotalCount = StatStorage.GetTotalCount()
 	result.Ios.PushSuccess = StatStorage.GetIosSuccess()
 	result.Ios.PushError = StatStorage.GetIosError()
 	result.Android.PushSuccess = StatStorage.GetAndroidSuccess()
 	result.Android.PushError = StatStorage.GetAndroidError()
+	result.Providers = make(map[string]ProviderStatus, len(config.Providers))
+	for _, name := range config.Providers {
+		result.Providers[name] = ProviderStatus{
+			PushSuccess: StatStorage.GetProviderSuccess(name),
+			PushError:   StatStorage.GetProviderError(name),
+		}
+	}
+	result.Android.Paused = isOutage(PlatFormAndroid)
+	result.Ios.Paused = isOutage(PlatFormIos)
+	result.Android.FailedOver = isFailedOver(PlatFormAndroid)
+	result.Ios.FailedOver = isFailedOver(PlatFormIos)
+	result.Maintenance = IsMaintenance()
 
 	c.JSON(http.StatusOK, result)
 }
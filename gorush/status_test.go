@@ -1,6 +1,8 @@
 package gorush
 
 import (
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/storage/memory"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -96,6 +98,21 @@ func TestDefaultEngine(t *testing.T) {
 	assert.Equal(t, int64(500), val)
 }
 
+func TestRegisterStatBackend(t *testing.T) {
+	var engine string
+	RegisterStatBackend("custom", func(conf config.ConfYaml) Storage {
+		engine = conf.Stat.Engine
+		return memory.New()
+	})
+
+	PushConf.Stat.Engine = "custom"
+	assert.NoError(t, InitAppStatus())
+	assert.Equal(t, "custom", engine)
+
+	StatStorage.AddTotalCount(100)
+	assert.Equal(t, int64(100), StatStorage.GetTotalCount())
+}
+
 func TestStatForBoltDBEngine(t *testing.T) {
 	var val int64
 	PushConf.Stat.Engine = "boltdb"
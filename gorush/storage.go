@@ -1,5 +1,15 @@
 package gorush
 
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/appleboy/gorush/storage/boltdb"
+	"github.com/appleboy/gorush/storage/buntdb"
+	"github.com/appleboy/gorush/storage/leveldb"
+	"github.com/appleboy/gorush/storage/memory"
+	"github.com/appleboy/gorush/storage/redis"
+	"sync"
+)
+
 // Storage interface
 type Storage interface {
 	Init() error
@@ -9,9 +19,59 @@ type Storage interface {
 	AddIosError(int64)
 	AddAndroidSuccess(int64)
 	AddAndroidError(int64)
+	AddProviderSuccess(string, int64)
+	AddProviderError(string, int64)
 	GetTotalCount() int64
 	GetIosSuccess() int64
 	GetIosError() int64
 	GetAndroidSuccess() int64
 	GetAndroidError() int64
+	GetProviderSuccess(string) int64
+	GetProviderError(string) int64
+}
+
+// StatBackendFactory create storage of stat engine from config.
+type StatBackendFactory func(config.ConfYaml) Storage
+
+var (
+	statBackendsLock sync.RWMutex
+	statBackends     = map[string]StatBackendFactory{
+		"memory": func(config.ConfYaml) Storage {
+			return memory.New()
+		},
+		"redis": func(conf config.ConfYaml) Storage {
+			return redis.New(conf)
+		},
+		"boltdb": func(conf config.ConfYaml) Storage {
+			return boltdb.New(conf)
+		},
+		"buntdb": func(conf config.ConfYaml) Storage {
+			return buntdb.New(conf)
+		},
+		"leveldb": func(conf config.ConfYaml) Storage {
+			return leveldb.New(conf)
+		},
+	}
+)
+
+// RegisterStatBackend add custom stat engine which can be selected by name
+// in stat engine config. Register the same name again will replace it.
+func RegisterStatBackend(name string, factory StatBackendFactory) {
+	statBackendsLock.Lock()
+	defer statBackendsLock.Unlock()
+
+	statBackends[name] = factory
+}
+
+// newStatStorage create storage of stat engine, default as memory.
+func newStatStorage(conf config.ConfYaml) Storage {
+	statBackendsLock.RLock()
+	factory, ok := statBackends[conf.Stat.Engine]
+	statBackendsLock.RUnlock()
+
+	if !ok {
+		return memory.New()
+	}
+
+	return factory(conf)
 }
@@ -0,0 +1,201 @@
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"github.com/appleboy/gorush/internal/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// expectDelim read next token of decoder and check it is delim.
+func expectDelim(dec *json.StreamDecoder, delim json.Delim) error {
+	token, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expect %s but got %v", delim, token)
+	}
+
+	return nil
+}
+
+// decodeNotifications decode notifications array of request body one by one
+// and call fn with index of each notification, return number of notifications.
+func decodeNotifications(body io.Reader, fn func(int, PushNotification) error) (int, error) {
+	var count int
+
+	dec := json.NewStreamDecoder(body)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return count, err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+
+		if err != nil {
+			return count, err
+		}
+
+		if key, _ := token.(string); key != "notifications" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return count, err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return count, err
+		}
+
+		for dec.More() {
+			var notification PushNotification
+			if err := dec.Decode(&notification); err != nil {
+				return count, err
+			}
+
+			if err := fn(count, notification); err != nil {
+				return count, err
+			}
+
+			count++
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// streamError is error of notification in stream push with status code of response.
+type streamError struct {
+	code int
+	err  error
+}
+
+func (e *streamError) Error() string {
+	return e.err.Error()
+}
+
+// abortStream response error of stream push like pushHandler, with number of
+// notifications queued before the error, which are still sent.
+func abortStream(c *gin.Context, code int, err error, queued int) {
+	result := gin.H{
+		"code":    code,
+		"message": err.Error(),
+		"queued":  queued,
+	}
+
+	if msgErr, ok := err.(*MessageError); ok {
+		result["error_code"] = msgErr.Code
+		result["field"] = msgErr.Field
+	}
+
+	recordRejected(c, code, err.Error())
+	c.JSON(code, result)
+	c.Abort()
+}
+
+// streamPushHandler queue notifications while parsing request body, so memory
+// usage doesn't grow with size of request. Parsing stops at the first notification
+// over limit or invalid, and the error response has number of notifications queued
+// before it, which are still sent.
+func streamPushHandler(c *gin.Context) {
+	var msg string
+	var queued int
+	var warnings []string
+	var invalidTokens []InvalidToken
+
+	requestID, traceParent := traceHeaders(c)
+
+	count, err := decodeNotifications(c.Request.Body, func(i int, notification PushNotification) error {
+		if int64(i) >= PushConf.Core.MaxNotification {
+			return &streamError{
+				code: http.StatusBadRequest,
+				err:  fmt.Errorf("Number of notifications over limit(%d)", PushConf.Core.MaxNotification),
+			}
+		}
+
+		notifications, invalid, code, err := validateNotification(i, notification)
+
+		if err != nil {
+			return &streamError{
+				code: code,
+				err:  notificationError(i, err),
+			}
+		}
+
+		invalidTokens = append(invalidTokens, invalid...)
+
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		for j := range notifications {
+			for _, warning := range LintMessage(notifications[j]) {
+				msg = fmt.Sprintf("notifications[%d]: %s", i, warning)
+				LogAccess.Warn(msg)
+				warnings = append(warnings, msg)
+			}
+
+			notifications[j].RequestID = requestID
+			notifications[j].TraceParent = traceParent
+		}
+
+		queueNotification(RequestPush{
+			Notifications: notifications,
+		})
+		queued++
+
+		return nil
+	})
+
+	if err != nil {
+		if streamErr, ok := err.(*streamError); ok {
+			LogAccess.Debug(streamErr.Error())
+			abortStream(c, streamErr.code, streamErr.err, queued)
+			return
+		}
+
+		if err.Error() == "http: request body too large" {
+			msg = fmt.Sprintf("Request body size over limit(%d)", PushConf.Core.MaxBodySize)
+			LogAccess.Debug(msg)
+			abortStream(c, http.StatusRequestEntityTooLarge, errors.New(msg), queued)
+			return
+		}
+
+		msg = fmt.Sprintf("Can't parse notifications[%d]: %s", count, err.Error())
+		LogAccess.Debug(msg)
+		abortStream(c, http.StatusBadRequest, errors.New(msg), queued)
+		return
+	}
+	if count == 0 {
+		msg = "Notifications field is empty."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	result := gin.H{
+		"success":    "ok",
+		"request_id": requestID,
+	}
+
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	if len(invalidTokens) > 0 {
+		result["invalid_tokens"] = invalidTokens
+	}
+
+	c.JSON(http.StatusOK, result)
+}
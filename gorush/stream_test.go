@@ -0,0 +1,44 @@
+package gorush
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNotifications(t *testing.T) {
+	var messages []string
+
+	body := `{"extra":{"a":[1,2]},"notifications":[{"tokens":["aaaaa"],"platform":1,"message":"Hello"},{"tokens":["bbbbb"],"platform":2,"message":"World"}]}`
+	count, err := decodeNotifications(strings.NewReader(body), func(i int, notification PushNotification) error {
+		messages = append(messages, notification.Message)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"Hello", "World"}, messages)
+
+	// stop by callback
+	count, err = decodeNotifications(strings.NewReader(body), func(i int, notification PushNotification) error {
+		if i > 0 {
+			return errors.New("over limit")
+		}
+		return nil
+	})
+
+	assert.Equal(t, "over limit", err.Error())
+	assert.Equal(t, 1, count)
+
+	// wrong format
+	_, err = decodeNotifications(strings.NewReader(`{"notifications":{}}`), func(i int, notification PushNotification) error {
+		return nil
+	})
+	assert.Error(t, err)
+
+	_, err = decodeNotifications(strings.NewReader(`{"notifications":[{"tokens":`), func(i int, notification PushNotification) error {
+		return nil
+	})
+	assert.Error(t, err)
+}
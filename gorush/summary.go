@@ -0,0 +1,115 @@
+package gorush
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// summaryMax is max number of request summaries kept in memory, the oldest is removed first.
+var summaryMax = 1000
+
+// PushSummary is aggregate push result of request.
+type PushSummary struct {
+	RequestID   string           `json:"request_id"`
+	PushSuccess int64            `json:"push_success"`
+	PushError   int64            `json:"push_error"`
+	Errors      map[string]int64 `json:"errors,omitempty"`
+	// Variants is push counts of A/B testing variants of request.
+	Variants map[string]VariantStatus `json:"variants,omitempty"`
+	// Duration is milliseconds between first and last push result.
+	Duration int64 `json:"duration"`
+	// Throughput is push results per second.
+	Throughput float64 `json:"throughput"`
+
+	start time.Time
+	end   time.Time
+}
+
+var (
+	summariesLock sync.Mutex
+	summaries     = map[string]*PushSummary{}
+	summaryOrder  []string
+)
+
+// addSummary record push result of request, error is counted by error message.
+func addSummary(requestID, status string, errPush error) {
+	if requestID == "" {
+		return
+	}
+
+	now := time.Now()
+
+	summariesLock.Lock()
+	defer summariesLock.Unlock()
+
+	summary, ok := summaries[requestID]
+	if !ok {
+		if len(summaryOrder) >= summaryMax {
+			delete(summaries, summaryOrder[0])
+			summaryOrder = summaryOrder[1:]
+		}
+
+		summary = &PushSummary{
+			RequestID: requestID,
+			Errors:    map[string]int64{},
+			start:     now,
+		}
+		summaries[requestID] = summary
+		summaryOrder = append(summaryOrder, requestID)
+	}
+
+	summary.end = now
+
+	switch status {
+	case SucceededPush:
+		summary.PushSuccess++
+	case FailedPush:
+		summary.PushError++
+		if errPush != nil {
+			summary.Errors[errPush.Error()]++
+		}
+	}
+}
+
+// getSummary return copy of summary of request.
+func getSummary(requestID string) (PushSummary, bool) {
+	summariesLock.Lock()
+	defer summariesLock.Unlock()
+
+	summary, ok := summaries[requestID]
+	if !ok {
+		return PushSummary{}, false
+	}
+
+	result := *summary
+	result.Errors = make(map[string]int64, len(summary.Errors))
+	for reason, count := range summary.Errors {
+		result.Errors[reason] = count
+	}
+	result.Variants = getVariantStats(requestID)
+
+	duration := summary.end.Sub(summary.start)
+	result.Duration = int64(duration / time.Millisecond)
+	if duration > 0 {
+		result.Throughput = float64(summary.PushSuccess+summary.PushError) / duration.Seconds()
+	}
+
+	return result, true
+}
+
+func summaryHandler(c *gin.Context) {
+	var msg string
+
+	summary, ok := getSummary(c.Query("request_id"))
+
+	if !ok {
+		msg = "Summary of request is not found."
+		LogAccess.Debug(msg)
+		abortWithError(c, http.StatusNotFound, msg)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
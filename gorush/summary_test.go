@@ -0,0 +1,47 @@
+package gorush
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAddSummary(t *testing.T) {
+	addSummary("", SucceededPush, nil)
+	_, ok := getSummary("")
+	assert.False(t, ok)
+
+	addSummary("summary-request", SucceededPush, nil)
+	time.Sleep(10 * time.Millisecond)
+	addSummary("summary-request", FailedPush, errors.New("BadDeviceToken"))
+	addSummary("summary-request", FailedPush, errors.New("BadDeviceToken"))
+
+	summary, ok := getSummary("summary-request")
+	assert.True(t, ok)
+	assert.Equal(t, "summary-request", summary.RequestID)
+	assert.Equal(t, int64(1), summary.PushSuccess)
+	assert.Equal(t, int64(2), summary.PushError)
+	assert.Equal(t, int64(2), summary.Errors["BadDeviceToken"])
+	assert.True(t, summary.Duration >= 10)
+	assert.True(t, summary.Throughput > 0)
+}
+
+func TestSummaryMax(t *testing.T) {
+	defer func(max int) {
+		summaryMax = max
+	}(summaryMax)
+
+	summaryMax = 2
+	summaries = map[string]*PushSummary{}
+	summaryOrder = nil
+
+	addSummary("summary-request", SucceededPush, nil)
+	addSummary("request-2", SucceededPush, nil)
+	addSummary("request-3", SucceededPush, nil)
+
+	_, ok := getSummary("summary-request")
+	assert.False(t, ok)
+	_, ok = getSummary("request-3")
+	assert.True(t, ok)
+}
@@ -0,0 +1,62 @@
+package gorush
+
+// TeamsMessage is message card of Microsoft Teams incoming webhook.
+// ref: https://docs.microsoft.com/outlook/actionable-messages/message-card-reference
+type TeamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	Title      string `json:"title,omitempty"`
+	Text       string `json:"text"`
+	ThemeColor string `json:"themeColor,omitempty"`
+}
+
+// GetTeamsMessage use for define Microsoft Teams message card.
+func GetTeamsMessage(req PushNotification) TeamsMessage {
+	message := TeamsMessage{
+		Type:    "MessageCard",
+		Context: "http://schema.org/extensions",
+		Summary: req.Message,
+		Title:   req.Title,
+		Text:    req.Message,
+	}
+
+	if len(req.Title) > 0 {
+		message.Summary = req.Title
+	}
+
+	if req.Priority.IsHigh() {
+		message.ThemeColor = "D70000"
+	}
+
+	return message
+}
+
+// PushToTeams provide send notification to Microsoft Teams, tokens are webhook URLs or IDs.
+func PushToTeams(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for Teams")
+
+	var isError bool
+
+	message := GetTeamsMessage(req)
+
+	for _, token := range req.Tokens {
+		url, err := webhookURL(PushConf.Teams.Webhooks, token)
+
+		if err == nil {
+			err = postJSON(url, nil, message)
+		}
+
+		if err != nil {
+			LogPush(FailedPush, token, req, err)
+			StatStorage.AddProviderError("teams", 1)
+			isError = true
+			continue
+		}
+
+		LogPush(SucceededPush, token, req, nil)
+		StatStorage.AddProviderSuccess("teams", 1)
+	}
+
+	return isError
+}
@@ -0,0 +1,68 @@
+package gorush
+
+import (
+	"encoding/json"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTeamsMessage(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"ops"},
+		Platform: PlatFormTeams,
+		Message:  "Welcome",
+		Title:    "Gorush",
+		Priority: "high",
+	}
+
+	message := GetTeamsMessage(req)
+
+	assert.Equal(t, "MessageCard", message.Type)
+	assert.Equal(t, "Gorush", message.Summary)
+	assert.Equal(t, "Gorush", message.Title)
+	assert.Equal(t, "Welcome", message.Text)
+	assert.Equal(t, "D70000", message.ThemeColor)
+}
+
+func TestPushToTeams(t *testing.T) {
+	var message TeamsMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Teams.Enabled = true
+	PushConf.Teams.Webhooks = map[string]string{
+		"ops":  ts.URL,
+		"gone": ts.URL + "/gone",
+	}
+	InitAppStatus()
+
+	req := PushNotification{
+		Tokens:   []string{ts.URL},
+		Platform: PlatFormTeams,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, PushToTeams(req))
+	assert.Equal(t, "Welcome", message.Summary)
+
+	req.Tokens = []string{"gone"}
+	assert.True(t, PushToTeams(req))
+
+	// URL not in config is rejected
+	req.Tokens = []string{ts.URL + "/other"}
+	assert.True(t, PushToTeams(req))
+
+	assert.Equal(t, int64(1), StatStorage.GetProviderSuccess("teams"))
+	assert.Equal(t, int64(2), StatStorage.GetProviderError("teams"))
+}
@@ -0,0 +1,71 @@
+package gorush
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// TelegramMessage is request of Telegram sendMessage method.
+// ref: https://core.telegram.org/bots/api#sendmessage
+type TelegramMessage struct {
+	ChatID              string `json:"chat_id"`
+	Text                string `json:"text"`
+	ParseMode           string `json:"parse_mode,omitempty"`
+	DisableNotification bool   `json:"disable_notification,omitempty"`
+}
+
+// GetTelegramMessage use for define Telegram message of chat, title is shown in bold.
+func GetTelegramMessage(req PushNotification, chatID string) TelegramMessage {
+	text := html.EscapeString(req.Message)
+	if len(req.Title) > 0 {
+		text = "<b>" + html.EscapeString(req.Title) + "</b>\n" + text
+	}
+
+	return TelegramMessage{
+		ChatID:              chatID,
+		Text:                text,
+		ParseMode:           "HTML",
+		DisableNotification: req.Priority.IsNormal(),
+	}
+}
+
+// redactTelegramError hide bot token in URL of HTTP client error, so it is not
+// leaked to push logs, callbacks and events.
+func redactTelegramError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	return &url.Error{
+		Op:  urlErr.Op,
+		URL: strings.TrimSuffix(PushConf.Telegram.APIURL, "/") + "/bot" + redactedValue + "/sendMessage",
+		Err: urlErr.Err,
+	}
+}
+
+// PushToTelegram provide send notification by Telegram bot, tokens are chat IDs.
+func PushToTelegram(req PushNotification) bool {
+	LogAccess.Debug("Start push notification for Telegram")
+
+	var isError bool
+
+	endpoint := strings.TrimSuffix(PushConf.Telegram.APIURL, "/") + "/bot" + PushConf.Telegram.BotToken + "/sendMessage"
+
+	for _, chatID := range req.Tokens {
+		err := postJSON(endpoint, nil, GetTelegramMessage(req, chatID))
+
+		if err != nil {
+			LogPush(FailedPush, chatID, req, redactTelegramError(err))
+			StatStorage.AddProviderError("telegram", 1)
+			isError = true
+			continue
+		}
+
+		LogPush(SucceededPush, chatID, req, nil)
+		StatStorage.AddProviderSuccess("telegram", 1)
+	}
+
+	return isError
+}
@@ -0,0 +1,96 @@
+package gorush
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTelegramMessage(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"123456"},
+		Platform: PlatFormTelegram,
+		Message:  "1 < 2",
+		Title:    "Gorush",
+		Priority: "normal",
+	}
+
+	message := GetTelegramMessage(req, "123456")
+
+	assert.Equal(t, "123456", message.ChatID)
+	assert.Equal(t, "<b>Gorush</b>\n1 &lt; 2", message.Text)
+	assert.Equal(t, "HTML", message.ParseMode)
+	assert.True(t, message.DisableNotification)
+
+	req.Title = ""
+	req.Priority = ""
+	message = GetTelegramMessage(req, "123456")
+
+	assert.Equal(t, "1 &lt; 2", message.Text)
+	assert.False(t, message.DisableNotification)
+}
+
+func TestPushToTelegram(t *testing.T) {
+	var path string
+	var message TelegramMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&message)
+
+		if message.ChatID == "000000" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Telegram.Enabled = true
+	InitAppStatus()
+	PushConf.Telegram.BotToken = "123:ABC"
+	PushConf.Telegram.APIURL = ts.URL
+
+	req := PushNotification{
+		Tokens:   []string{"123456"},
+		Platform: PlatFormTelegram,
+		Message:  "Welcome",
+	}
+
+	assert.False(t, PushToTelegram(req))
+	assert.Equal(t, "/bot123:ABC/sendMessage", path)
+	assert.Equal(t, "123456", message.ChatID)
+
+	req.Tokens = []string{"000000"}
+	assert.True(t, PushToTelegram(req))
+}
+
+func TestMissingTelegramBotToken(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	PushConf.Telegram.Enabled = true
+
+	err := CheckPushConf()
+
+	assert.Error(t, err)
+	assert.Equal(t, "Missing Telegram bot token", err.Error())
+}
+
+func TestRedactTelegramError(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Telegram.BotToken = "123:ABC"
+	PushConf.Telegram.APIURL = "http://127.0.0.1:1"
+
+	err := postJSON(PushConf.Telegram.APIURL+"/bot123:ABC/sendMessage", nil, TelegramMessage{})
+	assert.Contains(t, err.Error(), "123:ABC")
+
+	err = redactTelegramError(err)
+	assert.NotContains(t, err.Error(), "123:ABC")
+	assert.Contains(t, err.Error(), "http://127.0.0.1:1/bot******/sendMessage")
+
+	// error of response status is kept
+	assert.Equal(t, "server response status code 400", redactTelegramError(errors.New("server response status code 400")).Error())
+}
@@ -0,0 +1,40 @@
+package gorush
+
+import "fmt"
+
+// testTokensOf return test device tokens of app, tokens of iOS certs
+// are selected by topic and default as test tokens of platform.
+func testTokensOf(req PushNotification) []string {
+	switch req.Platform {
+	case PlatFormIos:
+		for _, cert := range PushConf.Ios.Certs {
+			if cert.Topic == req.Topic && len(cert.TestTokens) > 0 {
+				return cert.TestTokens
+			}
+		}
+
+		return PushConf.Ios.TestTokens
+	case PlatFormAndroid:
+		return PushConf.Android.TestTokens
+	}
+
+	return nil
+}
+
+// applyTestOnly replace tokens of test_only notification with test device tokens
+// of app regardless of submitted tokens.
+func applyTestOnly(req *PushNotification) error {
+	if !req.TestOnly {
+		return nil
+	}
+
+	tokens := testTokensOf(*req)
+	if len(tokens) == 0 {
+		return newMessageError(ErrCodeNoTestTokens, "test_only", fmt.Sprintf("no test tokens of app %s", appOf(*req)))
+	}
+
+	req.Tokens = append([]string{}, tokens...)
+	req.To = ""
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplyTestOnly(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+	PushConf.Ios.TestTokens = []string{"test-ios"}
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.app", TestTokens: []string{"test-app"}},
+	}
+
+	req := PushNotification{
+		Platform: PlatFormIos,
+		Tokens:   []string{"aaaaa", "bbbbb"},
+		Message:  "Welcome",
+	}
+
+	// not test only
+	assert.NoError(t, applyTestOnly(&req))
+	assert.Equal(t, []string{"aaaaa", "bbbbb"}, req.Tokens)
+
+	req.TestOnly = true
+	assert.NoError(t, applyTestOnly(&req))
+	assert.Equal(t, []string{"test-ios"}, req.Tokens)
+
+	req.Topic = "com.example.app"
+	assert.NoError(t, applyTestOnly(&req))
+	assert.Equal(t, []string{"test-app"}, req.Tokens)
+
+	req = PushNotification{
+		Platform: PlatFormAndroid,
+		To:       "/topics/all",
+		Message:  "Welcome",
+		TestOnly: true,
+	}
+
+	err := applyTestOnly(&req)
+	assert.Error(t, err)
+	assert.Equal(t, "no test tokens of app ", err.Error())
+	assert.Equal(t, ErrCodeNoTestTokens, err.(*MessageError).Code)
+
+	PushConf.Android.TestTokens = []string{"test-android"}
+	assert.NoError(t, applyTestOnly(&req))
+	assert.Equal(t, []string{"test-android"}, req.Tokens)
+	assert.Equal(t, "", req.To)
+}
@@ -0,0 +1,242 @@
+package gorush
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// throttleWait is interval of worker to check the throttle state.
+var throttleWait = 10 * time.Millisecond
+
+// throttleState track send concurrency of app. The limit is halved when
+// provider throttles push and increased by one after as many successful
+// pushes as the limit, up to worker number.
+type throttleState struct {
+	sync.Mutex
+	platform  int
+	limit     int
+	active    int
+	successes int
+	backoff   time.Duration
+	until     time.Time
+}
+
+var (
+	throttles    = map[string]*throttleState{}
+	throttleLock sync.Mutex
+)
+
+var (
+	// retryUntil is end of Retry-After of the latest throttled response of each platform.
+	retryUntil     = map[int]time.Time{}
+	retryUntilLock sync.Mutex
+)
+
+// parseRetryAfter return duration of Retry-After header in seconds or HTTP date,
+// zero if it is empty or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
+
+// isThrottledStatus return true if provider response status means push is rate limited.
+func isThrottledStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// recordRetryAfter keep Retry-After of throttled response of platform,
+// backoff of throttled apps of platform lasts at least until then.
+func recordRetryAfter(platform int, res *http.Response) {
+	if !isThrottledStatus(res.StatusCode) {
+		return
+	}
+
+	now := time.Now()
+	after := parseRetryAfter(res.Header.Get("Retry-After"), now)
+	if after == 0 {
+		return
+	}
+
+	retryUntilLock.Lock()
+	defer retryUntilLock.Unlock()
+
+	if until := now.Add(after); until.After(retryUntil[platform]) {
+		retryUntil[platform] = until
+	}
+}
+
+// retryUntilOf return end of Retry-After of platform.
+func retryUntilOf(platform int) time.Time {
+	retryUntilLock.Lock()
+	defer retryUntilLock.Unlock()
+
+	return retryUntil[platform]
+}
+
+// isThrottledError return true if error of GCM request is HTTP 429 or 503,
+// GCM client returns status of non 200 response as error.
+func isThrottledError(err error) bool {
+	msg := err.Error()
+	for _, code := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		if strings.Contains(msg, fmt.Sprintf("%d %s", code, http.StatusText(code))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfterTransport record Retry-After of throttled responses of platform.
+type retryAfterTransport struct {
+	platform int
+	base     http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+
+	if err == nil {
+		recordRetryAfter(t.platform, res)
+	}
+
+	return res, err
+}
+
+// CloseIdleConnections close idle connections of base transport.
+func (t *retryAfterTransport) CloseIdleConnections() {
+	if transport, ok := t.base.(interface {
+		CloseIdleConnections()
+	}); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// maxConcurrency return max send concurrency of app.
+func maxConcurrency() int {
+	if PushConf.Core.WorkerNum > 0 {
+		return int(PushConf.Core.WorkerNum)
+	}
+
+	return 1
+}
+
+// minConcurrency return min send concurrency of app while throttled.
+func minConcurrency() int {
+	if min := PushConf.Core.Throttle.MinConcurrency; min > 0 && min < maxConcurrency() {
+		return min
+	}
+
+	return 1
+}
+
+// throttleOf return throttle state of app, create it if not exist.
+func throttleOf(platform int, app string) *throttleState {
+	key := typeForPlatForm(platform) + ":" + app
+
+	throttleLock.Lock()
+	defer throttleLock.Unlock()
+
+	state, ok := throttles[key]
+	if !ok {
+		state = &throttleState{platform: platform, limit: maxConcurrency()}
+		throttles[key] = state
+	}
+
+	return state
+}
+
+// acquireThrottle block worker while app is backing off or over its send
+// concurrency, return nil if throttle is disabled.
+func acquireThrottle(platform int, app string) *throttleState {
+	if !PushConf.Core.Throttle.Enabled {
+		return nil
+	}
+
+	state := throttleOf(platform, app)
+	for {
+		state.Lock()
+		if state.active < state.limit && !time.Now().Before(state.until) {
+			state.active++
+			state.Unlock()
+			return state
+		}
+		state.Unlock()
+
+		time.Sleep(throttleWait)
+	}
+}
+
+// release give back the send slot, throttled is true if provider rejected
+// push by rate limit. Backoff lasts at least until Retry-After of provider.
+func (s *throttleState) release(throttled bool) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.active--
+
+	if !throttled {
+		s.backoff = 0
+		s.successes++
+		if s.successes >= s.limit && s.limit < maxConcurrency() {
+			s.limit++
+			s.successes = 0
+		}
+		return
+	}
+
+	retry := retryUntilOf(s.platform)
+
+	// other workers hit the same throttle, already backing off.
+	if time.Now().Before(s.until) {
+		if retry.After(s.until) {
+			s.until = retry
+		}
+		return
+	}
+
+	s.successes = 0
+	s.limit = s.limit / 2
+	if s.limit < minConcurrency() {
+		s.limit = minConcurrency()
+	}
+
+	maxBackoff := time.Duration(PushConf.Core.Throttle.MaxBackoff) * time.Second
+	if s.backoff == 0 {
+		s.backoff = time.Duration(PushConf.Core.Throttle.Backoff) * time.Second
+	} else {
+		s.backoff *= 2
+	}
+
+	if maxBackoff > 0 && s.backoff > maxBackoff {
+		s.backoff = maxBackoff
+	}
+
+	s.until = time.Now().Add(s.backoff)
+	if retry.After(s.until) {
+		s.until = retry
+	}
+	LogError.Warn(fmt.Sprintf("push is throttled by provider, reduce concurrency to %d and back off %s", s.limit, s.until.Sub(time.Now())))
+}
@@ -0,0 +1,157 @@
+package gorush
+
+import (
+	"errors"
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestThrottleDisabled(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	throttle := acquireThrottle(PlatFormIos, "com.example.disabled")
+	assert.Nil(t, throttle)
+
+	// release of nil throttle is no-op
+	throttle.release(true)
+}
+
+func TestThrottleBackoffAndRampUp(t *testing.T) {
+	InitLog()
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.WorkerNum = 8
+	PushConf.Core.Throttle.Enabled = true
+	PushConf.Core.Throttle.MinConcurrency = 2
+	PushConf.Core.Throttle.Backoff = 0
+	throttleWait = time.Millisecond
+
+	app := "com.example.throttle"
+	throttle := acquireThrottle(PlatFormIos, app)
+	assert.Equal(t, 8, throttle.limit)
+	assert.Equal(t, 1, throttle.active)
+
+	throttle.release(true)
+	assert.Equal(t, 4, throttle.limit)
+	assert.Equal(t, 0, throttle.active)
+
+	acquireThrottle(PlatFormIos, app).release(true)
+	acquireThrottle(PlatFormIos, app).release(true)
+	assert.Equal(t, 2, throttle.limit)
+
+	// other app is not throttled
+	assert.Equal(t, 8, throttleOf(PlatFormIos, "com.example.other").limit)
+	assert.Equal(t, 8, throttleOf(PlatFormAndroid, app).limit)
+
+	// limit is increased after as many successes as the limit
+	acquireThrottle(PlatFormIos, app).release(false)
+	assert.Equal(t, 2, throttle.limit)
+	acquireThrottle(PlatFormIos, app).release(false)
+	assert.Equal(t, 3, throttle.limit)
+}
+
+func TestThrottleConcurrency(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.WorkerNum = 1
+	PushConf.Core.Throttle.Enabled = true
+	throttleWait = time.Millisecond
+
+	app := "com.example.concurrency"
+	throttle := acquireThrottle(PlatFormAndroid, app)
+
+	done := make(chan bool)
+	go func() {
+		acquireThrottle(PlatFormAndroid, app).release(false)
+		done <- true
+	}()
+
+	select {
+	case <-done:
+		t.Error("worker is not blocked over concurrency")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	throttle.release(false)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("worker is not released")
+	}
+}
+
+func TestThrottleMaxBackoff(t *testing.T) {
+	InitLog()
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Throttle.Enabled = true
+	PushConf.Core.Throttle.Backoff = 2
+	PushConf.Core.Throttle.MaxBackoff = 3
+
+	throttle := throttleOf(PlatFormIos, "com.example.backoff")
+	throttle.active = 3
+	throttle.release(true)
+	assert.Equal(t, 2*time.Second, throttle.backoff)
+
+	// throttle while backing off doesn't extend it
+	throttle.release(true)
+	assert.Equal(t, 2*time.Second, throttle.backoff)
+
+	throttle.until = time.Time{}
+	throttle.release(true)
+	assert.Equal(t, 3*time.Second, throttle.backoff)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+
+	date := now.Add(time.Minute).UTC().Format(http.TimeFormat)
+	after := parseRetryAfter(date, now)
+	assert.True(t, after > 58*time.Second && after <= time.Minute)
+}
+
+func TestIsThrottledError(t *testing.T) {
+	assert.True(t, isThrottledError(errors.New("429 Too Many Requests")))
+	assert.True(t, isThrottledError(errors.New("503 Service Unavailable")))
+	assert.False(t, isThrottledError(errors.New("500 Internal Server Error")))
+}
+
+func TestThrottleRetryAfter(t *testing.T) {
+	InitLog()
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.Throttle.Enabled = true
+	PushConf.Core.Throttle.Backoff = 1
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &retryAfterTransport{platform: PlatFormIos, base: http.DefaultTransport}}
+	res, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	// back off until Retry-After instead of 1 second
+	throttle := throttleOf(PlatFormIos, "com.example.retry")
+	throttle.active = 1
+	throttle.release(true)
+	assert.True(t, throttle.until.Sub(time.Now()) > 20*time.Second)
+
+	// other platform is not affected
+	throttle = throttleOf(PlatFormAndroid, "com.example.retry")
+	throttle.active = 1
+	throttle.release(true)
+	assert.True(t, throttle.until.Sub(time.Now()) <= time.Second)
+
+	retryUntilLock.Lock()
+	delete(retryUntil, PlatFormIos)
+	retryUntilLock.Unlock()
+}
@@ -0,0 +1,71 @@
+package gorush
+
+import "regexp"
+
+var (
+	apnsTokenPattern = regexp.MustCompile("^([0-9a-fA-F]{64}|[0-9a-fA-F]{160})$")
+	gcmTokenPattern  = regexp.MustCompile("^[0-9A-Za-z_:-]{32,}$")
+)
+
+// InvalidToken is device token rejected by format validation.
+type InvalidToken struct {
+	Index     int    `json:"index"`
+	Token     string `json:"token"`
+	ErrorCode string `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+// ValidateToken check format of iOS and Android device token.
+func ValidateToken(platform int, token string) error {
+	switch platform {
+	case PlatFormIos:
+		if !apnsTokenPattern.MatchString(token) {
+			return newMessageError(ErrCodeInvalidToken, "tokens", "the iOS token must be 64 or 160 hex characters")
+		}
+	case PlatFormAndroid:
+		if len(token) > 4096 || !gcmTokenPattern.MatchString(token) {
+			return newMessageError(ErrCodeInvalidToken, "tokens",
+				"the Android token must be 32 to 4096 characters of letters, digits, '_', '-' and ':'")
+		}
+	}
+
+	return nil
+}
+
+// filterTokens remove invalid tokens of notifications, notification without
+// any valid token is removed. Index of invalid token is index of notification.
+func filterTokens(notifications []PushNotification) ([]PushNotification, []InvalidToken) {
+	var invalid []InvalidToken
+
+	result := notifications[:0]
+	for i, notification := range notifications {
+		if len(notification.Tokens) == 0 {
+			result = append(result, notification)
+			continue
+		}
+
+		tokens := make([]string, 0, len(notification.Tokens))
+		for _, token := range notification.Tokens {
+			if err := ValidateToken(notification.Platform, token); err != nil {
+				invalid = append(invalid, InvalidToken{
+					Index:     i,
+					Token:     token,
+					ErrorCode: ErrCodeInvalidToken,
+					Error:     err.Error(),
+				})
+				continue
+			}
+
+			tokens = append(tokens, token)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		notification.Tokens = tokens
+		result = append(result, notification)
+	}
+
+	return result, invalid
+}
@@ -0,0 +1,41 @@
+package gorush
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	assert.NoError(t, ValidateToken(PlatFormIos, strings.Repeat("a", 64)))
+	assert.NoError(t, ValidateToken(PlatFormIos, strings.Repeat("F", 160)))
+	assert.Error(t, ValidateToken(PlatFormIos, strings.Repeat("a", 63)))
+	assert.Error(t, ValidateToken(PlatFormIos, strings.Repeat("g", 64)))
+
+	assert.NoError(t, ValidateToken(PlatFormAndroid, "dQw4w9WgXcQ:APA91bH-"+strings.Repeat("a", 140)))
+	assert.Error(t, ValidateToken(PlatFormAndroid, "aaaaa"))
+	assert.Error(t, ValidateToken(PlatFormAndroid, strings.Repeat("a", 40)+" "))
+}
+
+func TestFilterTokens(t *testing.T) {
+	valid := strings.Repeat("a", 64)
+
+	notifications, invalid := filterTokens([]PushNotification{
+		{
+			Platform: PlatFormIos,
+			Tokens:   []string{"aaaaa"},
+		},
+		{
+			Platform: PlatFormIos,
+			Tokens:   []string{valid, "bbbbb"},
+		},
+	})
+
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, []string{valid}, notifications[0].Tokens)
+	assert.Len(t, invalid, 2)
+	assert.Equal(t, 0, invalid[0].Index)
+	assert.Equal(t, "aaaaa", invalid[0].Token)
+	assert.Equal(t, 1, invalid[1].Index)
+	assert.Equal(t, "bbbbb", invalid[1].Token)
+}
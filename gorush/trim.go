@@ -0,0 +1,103 @@
+package gorush
+
+import "unicode/utf8"
+
+// maxPayloadSize is max bytes of APNs payload and GCM message.
+const maxPayloadSize = 4096
+
+// ellipsis is appended to truncated text.
+const ellipsis = "…"
+
+// trimPayloadOf return true if payload of notification app should be trimmed,
+// trim_payload of iOS certs entry is enabled for its topic.
+func trimPayloadOf(req PushNotification) bool {
+	switch req.Platform {
+	case PlatFormIos:
+		for _, cert := range PushConf.Ios.Certs {
+			if cert.Topic == req.Topic && cert.TrimPayload {
+				return true
+			}
+		}
+
+		return PushConf.Ios.TrimPayload
+	case PlatFormAndroid:
+		return PushConf.Android.TrimPayload
+	}
+
+	return false
+}
+
+// truncateText cut text to at most n bytes on rune boundary with ellipsis.
+func truncateText(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+
+	n -= len(ellipsis)
+	if n <= 0 {
+		return ""
+	}
+
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+
+	return text[:n] + ellipsis
+}
+
+// payloadSize return bytes of APNs payload or GCM message (without tokens) of notification.
+func payloadSize(req PushNotification) (int, error) {
+	switch req.Platform {
+	case PlatFormIos:
+		return iosPayloadSize(req)
+	case PlatFormAndroid:
+		return androidPayloadSize(req)
+	}
+
+	return 0, nil
+}
+
+// trimFields return body and title of notification in trimming order.
+func trimFields(req *PushNotification) []*string {
+	body, title := &req.Message, &req.Title
+
+	switch req.Platform {
+	case PlatFormIos:
+		if req.Alert.Body != "" {
+			body = &req.Alert.Body
+		}
+	case PlatFormAndroid:
+		if req.Notification.Body != "" {
+			body = &req.Notification.Body
+		}
+		if req.Title == "" {
+			title = &req.Notification.Title
+		}
+	}
+
+	return []*string{body, title}
+}
+
+// TrimPayload truncate body and then title of notification with ellipsis when
+// payload is over provider limit, instead of rejected by provider.
+// Return true if notification is truncated.
+func TrimPayload(req *PushNotification) bool {
+	if !trimPayloadOf(*req) {
+		return false
+	}
+
+	for _, field := range trimFields(req) {
+		for *field != "" {
+			size, err := payloadSize(*req)
+			if err != nil || size <= maxPayloadSize {
+				return req.Truncated
+			}
+
+			// escaped text may take more bytes in payload, check size again after cut.
+			*field = truncateText(*field, len(*field)-(size-maxPayloadSize))
+			req.Truncated = true
+		}
+	}
+
+	return req.Truncated
+}
@@ -0,0 +1,69 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "Hello", truncateText("Hello", 5))
+	assert.Equal(t, "He…", truncateText("Hello World", 2+len(ellipsis)))
+	assert.Equal(t, "", truncateText("Hello World", 2))
+
+	// cut on rune boundary
+	assert.Equal(t, "你…", truncateText("你好世界", 7))
+}
+
+func TestTrimPayloadDisabled(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormIos,
+		Message:  strings.Repeat("a", 5000),
+	}
+
+	assert.False(t, TrimPayload(&req))
+	assert.Equal(t, 5000, len(req.Message))
+}
+
+func TestTrimIOSPayload(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Ios.Certs = []config.SectionIosCert{
+		{Topic: "com.example.trim", TrimPayload: true},
+	}
+
+	req := PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormIos,
+		Topic:    "com.example.trim",
+		Title:    "Welcome",
+		Message:  strings.Repeat("\"quoted\" ", 1000),
+	}
+
+	assert.True(t, TrimPayload(&req))
+	assert.True(t, req.Truncated)
+	assert.True(t, strings.HasSuffix(req.Message, ellipsis))
+	assert.Equal(t, "Welcome", req.Title)
+
+	size, err := payloadSize(req)
+	assert.NoError(t, err)
+	assert.True(t, size <= maxPayloadSize)
+
+	// other topic is not trimmed
+	req = PushNotification{
+		Tokens:   []string{"aaaaa"},
+		Platform: PlatFormIos,
+		Topic:    "com.example.other",
+		Message:  strings.Repeat("a", 5000),
+	}
+	assert.False(t, TrimPayload(&req))
+
+	// small payload is not trimmed
+	PushConf.Ios.TrimPayload = true
+	req.Message = "Hello"
+	assert.False(t, TrimPayload(&req))
+	assert.Equal(t, "Hello", req.Message)
+}
@@ -0,0 +1,66 @@
+package gorush
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// validateNotification apply test_only, check limits, app and message of
+// notification, then split auto platform and filter invalid tokens if validate_token
+// is enabled. Notifications split from auto platform are checked again for their platform. Index of invalid tokens is i, index of notification in request.
+// It return HTTP status code of error.
+func validateNotification(i int, req PushNotification) ([]PushNotification, []InvalidToken, int, error) {
+	if err := applyTestOnly(&req); err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	if err := CheckLimit(req); err != nil {
+		return nil, nil, http.StatusRequestEntityTooLarge, err
+	}
+
+	if err := checkApp(req); err != nil {
+		return nil, nil, http.StatusForbidden, err
+	}
+
+	if err := CheckMessage(req); err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+
+	notifications, invalid := resolveAuto([]PushNotification{req})
+	if req.Platform == PlatFormAuto {
+		for _, notification := range notifications {
+			if err := CheckMessage(notification); err != nil {
+				return nil, nil, http.StatusBadRequest, err
+			}
+		}
+	}
+
+	if PushConf.Core.ValidateToken {
+		var filtered []InvalidToken
+		notifications, filtered = filterTokens(notifications)
+		invalid = append(invalid, filtered...)
+	}
+
+	for j := range invalid {
+		invalid[j].Index = i
+	}
+
+	return notifications, invalid, http.StatusOK, nil
+}
+
+// notificationError prefix error with index of notification in request,
+// error code and field of MessageError are kept.
+func notificationError(i int, err error) error {
+	msg := fmt.Sprintf("notifications[%d]: %s", i, err.Error())
+
+	if msgErr, ok := err.(*MessageError); ok {
+		return &MessageError{
+			Code:    msgErr.Code,
+			Field:   msgErr.Field,
+			Message: msg,
+		}
+	}
+
+	return errors.New(msg)
+}
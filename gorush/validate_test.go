@@ -0,0 +1,71 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateNotification(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	PushConf.Core.ValidateToken = true
+	PushConf.Ios.TestTokens = []string{strings.Repeat("a", 64)}
+
+	req := PushNotification{
+		Platform: PlatFormIos,
+		Tokens:   []string{"bbbbb"},
+		Message:  "Welcome",
+		TestOnly: true,
+	}
+
+	// test_only replace tokens before validation
+	notifications, invalid, code, err := validateNotification(3, req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, invalid, 0)
+	assert.Equal(t, PushConf.Ios.TestTokens, notifications[0].Tokens)
+
+	// index of invalid token is index of notification in request
+	req.TestOnly = false
+	notifications, invalid, code, err = validateNotification(3, req)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 0)
+	assert.Len(t, invalid, 1)
+	assert.Equal(t, 3, invalid[0].Index)
+
+	req = PushNotification{
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+		TestOnly: true,
+	}
+
+	_, _, code, err = validateNotification(1, req)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "notifications[1]: no test tokens of app ", notificationError(1, err).Error())
+
+	// message is checked before queued
+	req = PushNotification{
+		Platform: PlatFormIos,
+		Tokens:   []string{"aaaaa"},
+		Message:  "Welcome",
+		PushType: "unknown",
+	}
+
+	_, _, code, err = validateNotification(0, req)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrCodeUnsupportedPush, err.(*MessageError).Code)
+
+	// notification split from auto platform is checked for its platform
+	req = PushNotification{
+		Platform: PlatFormAuto,
+		Tokens:   []string{strings.Repeat("a", 64)},
+		Message:  "Welcome",
+		ApnsID:   "invalid",
+	}
+
+	_, _, _, err = validateNotification(0, req)
+	assert.Equal(t, ErrCodeInvalidApnsID, err.(*MessageError).Code)
+}
@@ -0,0 +1,152 @@
+package gorush
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// Variant is message variant of A/B testing.
+type Variant struct {
+	Name    string `json:"name"`
+	Weight  int    `json:"weight"`
+	Message string `json:"message,omitempty"`
+	Title   string `json:"title,omitempty"`
+}
+
+// VariantStatus is push counts of variant.
+type VariantStatus struct {
+	PushSuccess int64 `json:"push_success"`
+	PushError   int64 `json:"push_error"`
+}
+
+// variantStatsMax is max number of variant stats kept in memory, the oldest is removed first.
+var variantStatsMax = 1000
+
+// variantKey is key of variant stats, variant names are scoped to request.
+type variantKey struct {
+	requestID string
+	name      string
+}
+
+var (
+	variantStatsLock  sync.Mutex
+	variantStats      = map[variantKey]*VariantStatus{}
+	variantStatsOrder []variantKey
+)
+
+// variantOfToken return index of variant assigned to token by weights,
+// the same token always get the same variant.
+func variantOfToken(token string, variants []Variant, total int) int {
+	h := fnv.New32a()
+	h.Write([]byte(token))
+	n := int(h.Sum32() % uint32(total))
+
+	for i, v := range variants {
+		if v.Weight <= 0 {
+			continue
+		}
+
+		if n < v.Weight {
+			return i
+		}
+
+		n -= v.Weight
+	}
+
+	return len(variants) - 1
+}
+
+// splitVariants split notification into one notification per variant.
+// Variants without positive weight are skipped.
+func splitVariants(req PushNotification) []PushNotification {
+	var total int
+	for _, v := range req.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+
+	if total == 0 {
+		return []PushNotification{req}
+	}
+
+	tokens := make([][]string, len(req.Variants))
+	for _, token := range req.Tokens {
+		i := variantOfToken(token, req.Variants, total)
+		tokens[i] = append(tokens[i], token)
+	}
+
+	var notifications []PushNotification
+	for i, v := range req.Variants {
+		if len(tokens[i]) == 0 {
+			continue
+		}
+
+		notification := req
+		notification.Tokens = tokens[i]
+		notification.Variants = nil
+		notification.Variant = v.Name
+
+		if v.Message != "" {
+			notification.Message = v.Message
+		}
+
+		if v.Title != "" {
+			notification.Title = v.Title
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications
+}
+
+// addVariantStat count push result of variant of request.
+func addVariantStat(requestID, name, status string) {
+	if requestID == "" || name == "" {
+		return
+	}
+
+	variantStatsLock.Lock()
+	defer variantStatsLock.Unlock()
+
+	key := variantKey{requestID: requestID, name: name}
+	stat, ok := variantStats[key]
+	if !ok {
+		if len(variantStatsOrder) >= variantStatsMax {
+			delete(variantStats, variantStatsOrder[0])
+			variantStatsOrder = variantStatsOrder[1:]
+		}
+
+		stat = &VariantStatus{}
+		variantStats[key] = stat
+		variantStatsOrder = append(variantStatsOrder, key)
+	}
+
+	switch status {
+	case SucceededPush:
+		stat.PushSuccess++
+	case FailedPush:
+		stat.PushError++
+	}
+}
+
+// getVariantStats return copy of push counts of variants of request.
+func getVariantStats(requestID string) map[string]VariantStatus {
+	variantStatsLock.Lock()
+	defer variantStatsLock.Unlock()
+
+	var result map[string]VariantStatus
+	for key, stat := range variantStats {
+		if key.requestID != requestID {
+			continue
+		}
+
+		if result == nil {
+			result = map[string]VariantStatus{}
+		}
+		result[key.name] = *stat
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+package gorush
+
+import (
+	"github.com/appleboy/gorush/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitVariants(t *testing.T) {
+	req := PushNotification{
+		Tokens:   []string{"aaaaa", "bbbbb", "ccccc", "ddddd", "eeeee", "fffff"},
+		Platform: PlatFormAndroid,
+		Message:  "Welcome",
+		Title:    "Hello",
+	}
+
+	// without variants
+	assert.Equal(t, []PushNotification{req}, splitVariants(req))
+
+	req.Variants = []Variant{
+		{Name: "a", Weight: 1, Message: "Welcome A"},
+		{Name: "b", Weight: 1, Title: "Hello B"},
+		{Name: "c", Weight: 0, Message: "Welcome C"},
+	}
+
+	notifications := splitVariants(req)
+
+	var count int
+	for _, notification := range notifications {
+		count += len(notification.Tokens)
+		assert.Nil(t, notification.Variants)
+
+		switch notification.Variant {
+		case "a":
+			assert.Equal(t, "Welcome A", notification.Message)
+			assert.Equal(t, "Hello", notification.Title)
+		case "b":
+			assert.Equal(t, "Welcome", notification.Message)
+			assert.Equal(t, "Hello B", notification.Title)
+		default:
+			t.Errorf("unexpected variant %s", notification.Variant)
+		}
+	}
+	assert.Equal(t, 6, count)
+
+	// assignment is deterministic
+	assert.Equal(t, notifications, splitVariants(req))
+}
+
+func TestVariantStats(t *testing.T) {
+	PushConf = config.BuildDefaultPushConf()
+	InitLog()
+
+	req := PushNotification{
+		Tokens:    []string{"aaaaa"},
+		Platform:  PlatFormAndroid,
+		Message:   "Welcome",
+		Variant:   "test-variant",
+		RequestID: "variant-request",
+	}
+
+	LogPush(SucceededPush, "aaaaa", req, nil)
+	LogPush(FailedPush, "aaaaa", req, nil)
+	LogPush(FailedPush, "aaaaa", req, nil)
+
+	stat := getVariantStats("variant-request")["test-variant"]
+	assert.Equal(t, int64(1), stat.PushSuccess)
+	assert.Equal(t, int64(2), stat.PushError)
+
+	// the same variant name of other request is counted separately.
+	req.RequestID = "other-request"
+	LogPush(SucceededPush, "aaaaa", req, nil)
+	assert.Equal(t, int64(1), getVariantStats("other-request")["test-variant"].PushSuccess)
+	assert.Equal(t, int64(1), getVariantStats("variant-request")["test-variant"].PushSuccess)
+
+	summary, _ := getSummary("variant-request")
+	assert.Equal(t, int64(2), summary.Variants["test-variant"].PushError)
+}
+
+func TestVariantStatsMax(t *testing.T) {
+	max := variantStatsMax
+	variantStatsMax = 2
+	defer func() { variantStatsMax = max }()
+
+	addVariantStat("max-request-1", "a", SucceededPush)
+	addVariantStat("max-request-2", "a", SucceededPush)
+	addVariantStat("max-request-3", "a", SucceededPush)
+
+	assert.Empty(t, getVariantStats("max-request-1"))
+	assert.Len(t, getVariantStats("max-request-3"), 1)
+}
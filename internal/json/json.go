@@ -0,0 +1,19 @@
+//go:build !jsoniter
+// +build !jsoniter
+
+// Package json wrap JSON serializer of gorush, build with jsoniter tag
+// to use github.com/json-iterator/go instead of encoding/json.
+package json
+
+import "encoding/json"
+
+var (
+	// Marshal is encoding/json Marshal.
+	Marshal = json.Marshal
+	// Unmarshal is encoding/json Unmarshal.
+	Unmarshal = json.Unmarshal
+	// NewDecoder is encoding/json NewDecoder.
+	NewDecoder = json.NewDecoder
+	// NewEncoder is encoding/json NewEncoder.
+	NewEncoder = json.NewEncoder
+)
@@ -0,0 +1,100 @@
+package json
+
+import (
+	"bytes"
+	"testing"
+)
+
+type benchAlert struct {
+	Body  string `json:"body,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+type benchPayload struct {
+	Alert benchAlert             `json:"alert"`
+	Badge int                    `json:"badge,omitempty"`
+	Sound string                 `json:"sound,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+type benchRequest struct {
+	Notifications []struct {
+		Tokens   []string               `json:"tokens"`
+		Platform int                    `json:"platform"`
+		Message  string                 `json:"message"`
+		Data     map[string]interface{} `json:"data,omitempty"`
+	} `json:"notifications"`
+}
+
+var benchBody = []byte(`{"notifications":[{"tokens":["aaaaa","bbbbb","ccccc"],"platform":1,"message":"Hello World","data":{"key":"value","count":1}}]}`)
+
+func TestMarshal(t *testing.T) {
+	body, err := Marshal(benchPayload{Badge: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != `{"alert":{},"badge":1}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestRawMessage(t *testing.T) {
+	body, err := Marshal(map[string]interface{}{"payload": RawMessage(`{"aps":{}}`)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != `{"payload":{"aps":{}}}` {
+		t.Errorf("unexpected body %s", body)
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	dec := NewStreamDecoder(bytes.NewReader(benchBody))
+
+	if token, err := dec.Token(); err != nil || token != Delim('{') {
+		t.Fatalf("unexpected token %v, error %v", token, err)
+	}
+
+	if token, _ := dec.Token(); token != "notifications" {
+		t.Fatalf("unexpected token %v", token)
+	}
+
+	var notifications []map[string]interface{}
+	if err := dec.Decode(&notifications); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifications) != 1 || notifications[0]["message"] != "Hello World" {
+		t.Errorf("unexpected notifications %v", notifications)
+	}
+
+	if dec.More() {
+		t.Error("unexpected more value")
+	}
+}
+
+func BenchmarkMarshalPayload(b *testing.B) {
+	payload := benchPayload{
+		Alert: benchAlert{Body: "Hello World", Title: "Gorush"},
+		Badge: 1,
+		Sound: "default",
+		Data:  map[string]interface{}{"key": "value", "count": 1},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Marshal(payload)
+	}
+}
+
+func BenchmarkDecodeRequest(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var req benchRequest
+		NewDecoder(bytes.NewReader(benchBody)).Decode(&req)
+	}
+}
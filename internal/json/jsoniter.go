@@ -0,0 +1,18 @@
+//go:build jsoniter
+// +build jsoniter
+
+package json
+
+import "github.com/json-iterator/go"
+
+var (
+	json = jsoniter.ConfigCompatibleWithStandardLibrary
+	// Marshal is jsoniter Marshal.
+	Marshal = json.Marshal
+	// Unmarshal is jsoniter Unmarshal.
+	Unmarshal = json.Unmarshal
+	// NewDecoder is jsoniter NewDecoder.
+	NewDecoder = json.NewDecoder
+	// NewEncoder is jsoniter NewEncoder.
+	NewEncoder = json.NewEncoder
+)
@@ -0,0 +1,26 @@
+package json
+
+import "errors"
+
+// RawMessage is raw encoded JSON value, it is marshaled as is by both serializers.
+type RawMessage []byte
+
+// MarshalJSON return m as the JSON encoding of m.
+func (m RawMessage) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+
+	return m, nil
+}
+
+// UnmarshalJSON set *m to a copy of data.
+func (m *RawMessage) UnmarshalJSON(data []byte) error {
+	if m == nil {
+		return errors.New("json.RawMessage: UnmarshalJSON on nil pointer")
+	}
+
+	*m = append((*m)[0:0], data...)
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package json
+
+import (
+	stdjson "encoding/json"
+	"io"
+)
+
+// Delim is JSON array or object delimiter, one of [ ] { or }.
+type Delim rune
+
+func (d Delim) String() string {
+	return string(d)
+}
+
+// StreamDecoder read tokens and values of JSON stream. Tokens are read by
+// encoding/json since jsoniter doesn't support them, values are decoded
+// by serializer of build.
+type StreamDecoder struct {
+	dec *stdjson.Decoder
+}
+
+// NewStreamDecoder return decoder reading from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{dec: stdjson.NewDecoder(r)}
+}
+
+// Token return next token of stream, delimiters are returned as Delim.
+func (d *StreamDecoder) Token() (interface{}, error) {
+	token, err := d.dec.Token()
+
+	if delim, ok := token.(stdjson.Delim); ok {
+		return Delim(delim), err
+	}
+
+	return token, err
+}
+
+// More return true if there is another element in current array or object.
+func (d *StreamDecoder) More() bool {
+	return d.dec.More()
+}
+
+// Decode read next value of stream into v.
+func (d *StreamDecoder) Decode(v interface{}) error {
+	var raw stdjson.RawMessage
+
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	return Unmarshal(raw, v)
+}
@@ -14,6 +14,16 @@ const (
 	AndroidErrorKey   = "gorush-android-error-count"
 )
 
+// ProviderSuccessKey is key of success count of provider, e.g. slack.
+func ProviderSuccessKey(name string) string {
+	return "gorush-" + name + "-success-count"
+}
+
+// ProviderErrorKey is key of error count of provider, e.g. slack.
+func ProviderErrorKey(name string) string {
+	return "gorush-" + name + "-error-count"
+}
+
 // New func implements the storage interface for gorush (https://github.com/appleboy/gorush)
 func New(config config.ConfYaml) *Storage {
 	return &Storage{
@@ -38,6 +48,10 @@ func (s *Storage) Reset() {
 	s.setBoltDB(IosErrorKey, 0)
 	s.setBoltDB(AndroidSuccessKey, 0)
 	s.setBoltDB(AndroidErrorKey, 0)
+	for _, name := range config.Providers {
+		s.setBoltDB(ProviderSuccessKey(name), 0)
+		s.setBoltDB(ProviderErrorKey(name), 0)
+	}
 }
 
 func (s *Storage) setBoltDB(key string, count int64) {
@@ -121,3 +135,31 @@ func (s *Storage) GetAndroidError() int64 {
 
 	return count
 }
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *Storage) AddProviderSuccess(name string, count int64) {
+	total := s.GetProviderSuccess(name) + count
+	s.setBoltDB(ProviderSuccessKey(name), total)
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *Storage) AddProviderError(name string, count int64) {
+	total := s.GetProviderError(name) + count
+	s.setBoltDB(ProviderErrorKey(name), total)
+}
+
+// GetProviderSuccess show success counts of provider notification.
+func (s *Storage) GetProviderSuccess(name string) int64 {
+	var count int64
+	s.getBoltDB(ProviderSuccessKey(name), &count)
+
+	return count
+}
+
+// GetProviderError show error counts of provider notification.
+func (s *Storage) GetProviderError(name string) int64 {
+	var count int64
+	s.getBoltDB(ProviderErrorKey(name), &count)
+
+	return count
+}
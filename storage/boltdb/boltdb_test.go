@@ -38,8 +38,20 @@ func TestBoltDBEngine(t *testing.T) {
 	val = boltDB.GetAndroidError()
 	assert.Equal(t, int64(50), val)
 
+	boltDB.AddProviderSuccess("slack", 60)
+	val = boltDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(60), val)
+
+	boltDB.AddProviderError("slack", 70)
+	val = boltDB.GetProviderError("slack")
+	assert.Equal(t, int64(70), val)
+
 	// test reset db
 	boltDB.Reset()
 	val = boltDB.GetAndroidError()
 	assert.Equal(t, int64(0), val)
+	val = boltDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(0), val)
+	val = boltDB.GetProviderError("slack")
+	assert.Equal(t, int64(0), val)
 }
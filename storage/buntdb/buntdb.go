@@ -16,6 +16,16 @@ const (
 	AndroidErrorKey   = "gorush-android-error-count"
 )
 
+// ProviderSuccessKey is key of success count of provider, e.g. slack.
+func ProviderSuccessKey(name string) string {
+	return "gorush-" + name + "-success-count"
+}
+
+// ProviderErrorKey is key of error count of provider, e.g. slack.
+func ProviderErrorKey(name string) string {
+	return "gorush-" + name + "-error-count"
+}
+
 // New func implements the storage interface for gorush (https://github.com/appleboy/gorush)
 func New(config config.ConfYaml) *Storage {
 	return &Storage{
@@ -40,6 +50,10 @@ func (s *Storage) Reset() {
 	s.setBuntDB(IosErrorKey, 0)
 	s.setBuntDB(AndroidSuccessKey, 0)
 	s.setBuntDB(AndroidErrorKey, 0)
+	for _, name := range config.Providers {
+		s.setBuntDB(ProviderSuccessKey(name), 0)
+		s.setBuntDB(ProviderErrorKey(name), 0)
+	}
 }
 
 func (s *Storage) setBuntDB(key string, count int64) {
@@ -132,3 +146,31 @@ func (s *Storage) GetAndroidError() int64 {
 
 	return count
 }
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *Storage) AddProviderSuccess(name string, count int64) {
+	total := s.GetProviderSuccess(name) + count
+	s.setBuntDB(ProviderSuccessKey(name), total)
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *Storage) AddProviderError(name string, count int64) {
+	total := s.GetProviderError(name) + count
+	s.setBuntDB(ProviderErrorKey(name), total)
+}
+
+// GetProviderSuccess show success counts of provider notification.
+func (s *Storage) GetProviderSuccess(name string) int64 {
+	var count int64
+	s.getBuntDB(ProviderSuccessKey(name), &count)
+
+	return count
+}
+
+// GetProviderError show error counts of provider notification.
+func (s *Storage) GetProviderError(name string) int64 {
+	var count int64
+	s.getBuntDB(ProviderErrorKey(name), &count)
+
+	return count
+}
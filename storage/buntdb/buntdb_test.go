@@ -43,7 +43,19 @@ func TestBuntDBEngine(t *testing.T) {
 	val = buntDB.GetAndroidError()
 	assert.Equal(t, int64(50), val)
 
+	buntDB.AddProviderSuccess("slack", 60)
+	val = buntDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(60), val)
+
+	buntDB.AddProviderError("slack", 70)
+	val = buntDB.GetProviderError("slack")
+	assert.Equal(t, int64(70), val)
+
 	buntDB.Reset()
 	val = buntDB.GetAndroidError()
 	assert.Equal(t, int64(0), val)
+	val = buntDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(0), val)
+	val = buntDB.GetProviderError("slack")
+	assert.Equal(t, int64(0), val)
 }
@@ -16,6 +16,16 @@ const (
 	AndroidErrorKey   = "gorush-android-error-count"
 )
 
+// ProviderSuccessKey is key of success count of provider, e.g. slack.
+func ProviderSuccessKey(name string) string {
+	return "gorush-" + name + "-success-count"
+}
+
+// ProviderErrorKey is key of error count of provider, e.g. slack.
+func ProviderErrorKey(name string) string {
+	return "gorush-" + name + "-error-count"
+}
+
 var dbPath string
 
 func setLevelDB(key string, count int64) {
@@ -61,6 +71,10 @@ func (s *Storage) Reset() {
 	setLevelDB(IosErrorKey, 0)
 	setLevelDB(AndroidSuccessKey, 0)
 	setLevelDB(AndroidErrorKey, 0)
+	for _, name := range config.Providers {
+		setLevelDB(ProviderSuccessKey(name), 0)
+		setLevelDB(ProviderErrorKey(name), 0)
+	}
 }
 
 // AddTotalCount record push notification count.
@@ -132,3 +146,31 @@ func (s *Storage) GetAndroidError() int64 {
 
 	return count
 }
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *Storage) AddProviderSuccess(name string, count int64) {
+	total := s.GetProviderSuccess(name) + count
+	setLevelDB(ProviderSuccessKey(name), total)
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *Storage) AddProviderError(name string, count int64) {
+	total := s.GetProviderError(name) + count
+	setLevelDB(ProviderErrorKey(name), total)
+}
+
+// GetProviderSuccess show success counts of provider notification.
+func (s *Storage) GetProviderSuccess(name string) int64 {
+	var count int64
+	getLevelDB(ProviderSuccessKey(name), &count)
+
+	return count
+}
+
+// GetProviderError show error counts of provider notification.
+func (s *Storage) GetProviderError(name string) int64 {
+	var count int64
+	getLevelDB(ProviderErrorKey(name), &count)
+
+	return count
+}
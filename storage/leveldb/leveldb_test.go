@@ -43,7 +43,19 @@ func TestLevelDBEngine(t *testing.T) {
 	val = levelDB.GetAndroidError()
 	assert.Equal(t, int64(50), val)
 
+	levelDB.AddProviderSuccess("slack", 60)
+	val = levelDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(60), val)
+
+	levelDB.AddProviderError("slack", 70)
+	val = levelDB.GetProviderError("slack")
+	assert.Equal(t, int64(70), val)
+
 	levelDB.Reset()
 	val = levelDB.GetAndroidError()
 	assert.Equal(t, int64(0), val)
+	val = levelDB.GetProviderSuccess("slack")
+	assert.Equal(t, int64(0), val)
+	val = levelDB.GetProviderError("slack")
+	assert.Equal(t, int64(0), val)
 }
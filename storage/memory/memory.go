@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"sync"
 	"sync/atomic"
 )
 
@@ -33,6 +34,11 @@ func New() *Storage {
 // Storage is interface structure
 type Storage struct {
 	stat *statApp
+
+	// counts of providers by name, e.g. slack
+	providerLock    sync.Mutex
+	providerSuccess map[string]int64
+	providerError   map[string]int64
 }
 
 // Init client storage.
@@ -47,6 +53,11 @@ func (s *Storage) Reset() {
 	atomic.StoreInt64(&s.stat.Ios.PushError, 0)
 	atomic.StoreInt64(&s.stat.Android.PushSuccess, 0)
 	atomic.StoreInt64(&s.stat.Android.PushError, 0)
+
+	s.providerLock.Lock()
+	s.providerSuccess = nil
+	s.providerError = nil
+	s.providerLock.Unlock()
 }
 
 // AddTotalCount record push notification count.
@@ -108,3 +119,41 @@ func (s *Storage) GetAndroidError() int64 {
 
 	return count
 }
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *Storage) AddProviderSuccess(name string, count int64) {
+	s.providerLock.Lock()
+	defer s.providerLock.Unlock()
+
+	if s.providerSuccess == nil {
+		s.providerSuccess = map[string]int64{}
+	}
+	s.providerSuccess[name] += count
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *Storage) AddProviderError(name string, count int64) {
+	s.providerLock.Lock()
+	defer s.providerLock.Unlock()
+
+	if s.providerError == nil {
+		s.providerError = map[string]int64{}
+	}
+	s.providerError[name] += count
+}
+
+// GetProviderSuccess show success counts of provider notification.
+func (s *Storage) GetProviderSuccess(name string) int64 {
+	s.providerLock.Lock()
+	defer s.providerLock.Unlock()
+
+	return s.providerSuccess[name]
+}
+
+// GetProviderError show error counts of provider notification.
+func (s *Storage) GetProviderError(name string) int64 {
+	s.providerLock.Lock()
+	defer s.providerLock.Unlock()
+
+	return s.providerError[name]
+}
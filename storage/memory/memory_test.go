@@ -36,8 +36,18 @@ func TestMemoryEngine(t *testing.T) {
 	val = memory.GetAndroidError()
 	assert.Equal(t, int64(5), val)
 
+	memory.AddProviderSuccess("slack", 6)
+	val = memory.GetProviderSuccess("slack")
+	assert.Equal(t, int64(6), val)
+
+	memory.AddProviderError("slack", 7)
+	val = memory.GetProviderError("slack")
+	assert.Equal(t, int64(7), val)
+
 	// test reset db
 	memory.Reset()
 	val = memory.GetTotalCount()
 	assert.Equal(t, int64(0), val)
+	val = memory.GetProviderError("slack")
+	assert.Equal(t, int64(0), val)
 }
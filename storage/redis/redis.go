@@ -16,6 +16,16 @@ const (
 	AndroidErrorKey   = "gorush-android-error-count"
 )
 
+// ProviderSuccessKey is key of success count of provider, e.g. slack.
+func ProviderSuccessKey(name string) string {
+	return "gorush-" + name + "-success-count"
+}
+
+// ProviderErrorKey is key of error count of provider, e.g. slack.
+func ProviderErrorKey(name string) string {
+	return "gorush-" + name + "-error-count"
+}
+
 //
 var redisClient *redis.Client
 
@@ -63,6 +73,10 @@ func (s *Storage) Reset() {
 	redisClient.Set(IosErrorKey, strconv.Itoa(0), 0)
 	redisClient.Set(AndroidSuccessKey, strconv.Itoa(0), 0)
 	redisClient.Set(AndroidErrorKey, strconv.Itoa(0), 0)
+	for _, name := range config.Providers {
+		redisClient.Set(ProviderSuccessKey(name), strconv.Itoa(0), 0)
+		redisClient.Set(ProviderErrorKey(name), strconv.Itoa(0), 0)
+	}
 }
 
 // AddTotalCount record push notification count.
@@ -134,3 +148,31 @@ func (s *Storage) GetAndroidError() int64 {
 
 	return count
 }
+
+// AddProviderSuccess record counts of success push notification of provider.
+func (s *Storage) AddProviderSuccess(name string, count int64) {
+	total := s.GetProviderSuccess(name) + count
+	redisClient.Set(ProviderSuccessKey(name), strconv.Itoa(int(total)), 0)
+}
+
+// AddProviderError record counts of error push notification of provider.
+func (s *Storage) AddProviderError(name string, count int64) {
+	total := s.GetProviderError(name) + count
+	redisClient.Set(ProviderErrorKey(name), strconv.Itoa(int(total)), 0)
+}
+
+// GetProviderSuccess show success counts of provider notification.
+func (s *Storage) GetProviderSuccess(name string) int64 {
+	var count int64
+	getInt64(ProviderSuccessKey(name), &count)
+
+	return count
+}
+
+// GetProviderError show error counts of provider notification.
+func (s *Storage) GetProviderError(name string) int64 {
+	var count int64
+	getInt64(ProviderErrorKey(name), &count)
+
+	return count
+}
@@ -49,8 +49,20 @@ func TestRedisEngine(t *testing.T) {
 	val = redis.GetAndroidError()
 	assert.Equal(t, int64(50), val)
 
+	redis.AddProviderSuccess("slack", 60)
+	val = redis.GetProviderSuccess("slack")
+	assert.Equal(t, int64(60), val)
+
+	redis.AddProviderError("slack", 70)
+	val = redis.GetProviderError("slack")
+	assert.Equal(t, int64(70), val)
+
 	// test reset db
 	redis.Reset()
 	val = redis.GetAndroidError()
 	assert.Equal(t, int64(0), val)
+	val = redis.GetProviderSuccess("slack")
+	assert.Equal(t, int64(0), val)
+	val = redis.GetProviderError("slack")
+	assert.Equal(t, int64(0), val)
 }